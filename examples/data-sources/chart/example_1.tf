@@ -0,0 +1,18 @@
+data "helm_chart" "redis" {
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+  version    = "~18.4"
+}
+
+resource "helm_release" "redis" {
+  name       = "redis"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+
+  # Pin the release to the version resolved at plan time
+  version = data.helm_chart.redis.resolved_version
+}
+
+output "redis_app_version" {
+  value = data.helm_chart.redis.app_version
+}
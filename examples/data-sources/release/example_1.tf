@@ -0,0 +1,16 @@
+data "helm_release" "ingress" {
+  name      = "ingress-nginx"
+  namespace = "ingress-nginx"
+
+  sensitive_values = [
+    "controller.admissionWebhooks.certificate",
+  ]
+}
+
+output "ingress_chart_version" {
+  value = data.helm_release.ingress.metadata.version
+}
+
+output "ingress_app_version" {
+  value = data.helm_release.ingress.metadata.app_version
+}
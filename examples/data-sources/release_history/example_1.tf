@@ -0,0 +1,14 @@
+data "helm_release_history" "ingress" {
+  name      = "ingress-nginx"
+  namespace = "ingress-nginx"
+
+  max_history = 5
+
+  sensitive_values = [
+    "controller.admissionWebhooks.certificate",
+  ]
+}
+
+output "ingress_chart_versions" {
+  value = { for r in data.helm_release_history.ingress.revisions : r.revision => r.version }
+}
@@ -0,0 +1,8 @@
+data "helm_releases" "failed" {
+  all_namespaces = true
+  statuses       = ["failed", "pending-install", "pending-upgrade"]
+}
+
+output "failed_releases" {
+  value = [for r in data.helm_releases.failed.releases : "${r.namespace}/${r.name} (${r.status})"]
+}
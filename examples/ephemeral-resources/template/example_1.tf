@@ -0,0 +1,30 @@
+ephemeral "helm_template" "credentials" {
+  name      = "credentials"
+  namespace = "default"
+  chart     = "./charts/credentials"
+
+  show_only = [
+    "templates/secret.yaml",
+  ]
+
+  set_sensitive = [
+    {
+      name  = "password"
+      value = var.database_password
+    }
+  ]
+}
+
+resource "helm_release" "app" {
+  name      = "app"
+  namespace = "default"
+  chart     = "./charts/app"
+
+  set_wo = [
+    {
+      name  = "extraManifests[0]"
+      value = ephemeral.helm_template.credentials.manifest
+    }
+  ]
+  set_wo_revision = 1
+}
@@ -0,0 +1,33 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+
+  postrender = {
+    kustomize = {
+      common_labels = {
+        "team" = "platform"
+      }
+
+      images = [
+        {
+          name     = "docker.io/bitnami/redis"
+          new_name = "registry.example.com/bitnami/redis"
+        }
+      ]
+
+      patches = [
+        {
+          patch = <<-EOT
+            - op: add
+              path: /spec/template/spec/priorityClassName
+              value: high-priority
+          EOT
+          target = {
+            kind = "StatefulSet"
+          }
+        }
+      ]
+    }
+  }
+}
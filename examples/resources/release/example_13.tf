@@ -0,0 +1,27 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+
+  postrender = {
+    steps = [
+      {
+        binary_path = "./labeler.sh"
+      },
+      {
+        kustomize = {
+          images = [
+            {
+              name     = "docker.io/bitnami/redis"
+              new_name = "registry.example.com/bitnami/redis"
+            }
+          ]
+        }
+      },
+      {
+        binary_path = "./policy-injector"
+        args        = ["--policy", "restricted"]
+      }
+    ]
+  }
+}
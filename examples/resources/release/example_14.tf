@@ -0,0 +1,19 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+
+  postrender = {
+    binary_path = "./bin/policy-injector"
+    working_dir = "${path.module}/postrender"
+    timeout     = 60
+
+    env = {
+      POLICY = "restricted"
+    }
+
+    env_sensitive = {
+      REGISTRY_TOKEN = var.registry_token
+    }
+  }
+}
@@ -0,0 +1,29 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+  version    = "6.0.1"
+
+  values = [
+    file("${path.module}/redis-values.yaml")
+  ]
+
+  values_object = {
+    replica = {
+      replicaCount = 3
+      nodeSelector = {
+        "kubernetes.io/os" = "linux"
+      }
+    }
+    commonLabels = {
+      team = "platform"
+    }
+  }
+
+  set = [
+    {
+      name  = "auth.enabled"
+      value = "false"
+    }
+  ]
+}
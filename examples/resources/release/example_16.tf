@@ -0,0 +1,15 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+  version    = "6.0.1"
+
+  values_files = [
+    # A file shipped in the chart archive
+    "values-production.yaml",
+    # A local file, relative to the working directory
+    "${path.module}/values/production.yaml",
+    # A remote file, fetched with the Helm getters
+    "https://config.example.com/redis/common.yaml",
+  ]
+}
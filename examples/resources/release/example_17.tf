@@ -0,0 +1,30 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+  version    = "6.0.1"
+
+  values = [
+    <<-EOT
+    master:
+      extraEnvVars:
+        - name: LOG_LEVEL
+          value: info
+        - name: TZ
+          value: UTC
+    EOT
+    ,
+    <<-EOT
+    master:
+      extraEnvVars:
+        - name: LOG_LEVEL
+          value: debug
+    EOT
+  ]
+
+  # The env vars are merged by name: LOG_LEVEL is set to debug and TZ is kept
+  list_merge = {
+    strategy = "merge_by_key"
+    key      = "name"
+  }
+}
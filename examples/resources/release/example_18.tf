@@ -0,0 +1,22 @@
+resource "helm_release" "example" {
+  name       = "my-redis-release"
+  repository = "https://charts.bitnami.com/bitnami"
+  chart      = "redis"
+  version    = "6.0.1"
+
+  set_sensitive = [
+    {
+      name  = "auth.password"
+      value = var.redis_password
+    }
+  ]
+}
+
+locals {
+  redis_values = jsondecode(helm_release.example.effective_values)
+}
+
+output "redis_port" {
+  # The default port of the chart, unless it is overridden
+  value = local.redis_values.master.service.ports.redis
+}
@@ -0,0 +1,9 @@
+resource "helm_repository" "bitnami" {
+  name = "bitnami"
+  url  = "https://charts.bitnami.com/bitnami"
+}
+
+resource "helm_release" "redis" {
+  name  = "redis"
+  chart = "${helm_repository.bitnami.name}/redis"
+}
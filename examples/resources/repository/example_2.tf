@@ -0,0 +1,7 @@
+resource "helm_repository" "private" {
+  name     = "private"
+  url      = "https://charts.example.com"
+  username = "ci"
+  password = var.chart_repository_password
+  ca_file  = "${path.module}/ca.crt"
+}
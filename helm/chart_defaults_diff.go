@@ -0,0 +1,241 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// maxDefaultValueLength is the length after which the values listed in the
+// chart defaults diff are truncated
+const maxDefaultValueLength = 80
+
+// valueChange is a default value added, removed or changed between two
+// versions of a chart
+type valueChange struct {
+	// action is '+' for added values, '-' for removed ones and '~' for
+	// changed ones
+	action   byte
+	path     string
+	old, new interface{}
+}
+
+// chartDefaultsDiff loads the chart at oldVersion and returns a warning
+// listing the default values and the values.schema.json paths the chart at
+// newChart and its subcharts change. Failing to load the previous chart only
+// produces a warning as the version may not be available anymore.
+func chartDefaultsDiff(ctx context.Context, meta *Meta, actionConfig *action.Configuration, plan *HelmReleaseModel, oldVersion string, newChart *chart.Chart) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	old := *plan
+	old.Version = types.StringValue(oldVersion)
+	old.Devel = types.BoolValue(false)
+
+	client := action.NewInstall(actionConfig)
+	cpo, chartName, cpoDiags := chartPathOptions(&old, meta, &client.ChartPathOptions)
+	if cpoDiags.HasError() {
+		return diags
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Loading chart %s %s to compare its default values", chartName, oldVersion))
+	oldChart, _, chartDiags := getChart(ctx, &old, meta, chartName, cpo)
+	if chartDiags.HasError() {
+		diags.AddWarning(
+			"Unable to compare chart default values",
+			fmt.Sprintf("The default values of chart %s %s could not be compared with version %s: %s", chartName, oldVersion, newChart.Metadata.Version, chartDiags.Errors()[0].Detail()),
+		)
+		return diags
+	}
+
+	values, schemas, err := diffChartDefaults(oldChart, newChart)
+	if err != nil {
+		diags.AddWarning(
+			"Unable to compare chart default values",
+			fmt.Sprintf("The default values of chart %s %s could not be compared with version %s: %s", chartName, oldVersion, newChart.Metadata.Version, err),
+		)
+		return diags
+	}
+	if len(values) == 0 && len(schemas) == 0 {
+		return diags
+	}
+
+	var detail strings.Builder
+	fmt.Fprintf(&detail, "Upgrading chart %s from %s to %s changes its defaults.\n", newChart.Metadata.Name, oldVersion, newChart.Metadata.Version)
+	if len(values) > 0 {
+		fmt.Fprintf(&detail, "\nDefault values:\n")
+		writeValueChanges(&detail, values)
+	}
+	for _, schema := range schemas {
+		if schema.chart == "" {
+			fmt.Fprintf(&detail, "\nvalues.schema.json:\n")
+		} else {
+			fmt.Fprintf(&detail, "\nvalues.schema.json of subchart %s:\n", schema.chart)
+		}
+		writeValueChanges(&detail, schema.changes)
+	}
+	diags.AddWarning("Chart default values changed", detail.String())
+	return diags
+}
+
+// diffChartDefaults returns the default values and the values.schema.json
+// paths changed between oldChart and newChart. The default values of the
+// subcharts are coalesced into the ones of the parent chart, the way Helm
+// renders them, so that changes to the defaults of a subchart are listed
+// under its name.
+func diffChartDefaults(oldChart, newChart *chart.Chart) ([]valueChange, []chartSchemaChanges, error) {
+	oldValues, err := chartutil.CoalesceValues(oldChart, map[string]interface{}{})
+	if err != nil {
+		return nil, nil, err
+	}
+	newValues, err := chartutil.CoalesceValues(newChart, map[string]interface{}{})
+	if err != nil {
+		return nil, nil, err
+	}
+	return diffValues("", oldValues, newValues), diffChartSchemas("", oldChart, newChart), nil
+}
+
+// chartSchemaChanges are the values.schema.json paths changed in a chart,
+// identified by its path in the values of the parent chart. The path of the
+// parent chart is empty.
+type chartSchemaChanges struct {
+	chart   string
+	changes []valueChange
+}
+
+// diffChartSchemas returns the changes to the values.schema.json of oldChart
+// and of its subcharts, matched by name, in newChart. Either chart is nil when
+// a subchart is only part of one of the versions.
+func diffChartSchemas(prefix string, oldChart, newChart *chart.Chart) []chartSchemaChanges {
+	var oldSchema, newSchema []byte
+	subcharts := map[string][2]*chart.Chart{}
+	if oldChart != nil {
+		oldSchema = oldChart.Schema
+		for _, subchart := range oldChart.Dependencies() {
+			pair := subcharts[subchart.Name()]
+			pair[0] = subchart
+			subcharts[subchart.Name()] = pair
+		}
+	}
+	if newChart != nil {
+		newSchema = newChart.Schema
+		for _, subchart := range newChart.Dependencies() {
+			pair := subcharts[subchart.Name()]
+			pair[1] = subchart
+			subcharts[subchart.Name()] = pair
+		}
+	}
+
+	var changes []chartSchemaChanges
+	if !jsonEqual(oldSchema, newSchema) {
+		var oldMap, newMap map[string]interface{}
+		_ = json.Unmarshal(oldSchema, &oldMap)
+		_ = json.Unmarshal(newSchema, &newMap)
+		if c := diffValues("", oldMap, newMap); len(c) > 0 {
+			changes = append(changes, chartSchemaChanges{chart: prefix, changes: c})
+		}
+	}
+
+	names := make([]string, 0, len(subcharts))
+	for name := range subcharts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		pair := subcharts[name]
+		changes = append(changes, diffChartSchemas(valuePath(prefix, name), pair[0], pair[1])...)
+	}
+	return changes
+}
+
+// diffValues returns the paths added, removed or changed between before and
+// after, sorted by path. Maps are compared key by key, any other value as a
+// whole.
+func diffValues(prefix string, before, after map[string]interface{}) []valueChange {
+	var changes []valueChange
+	for k, oldValue := range before {
+		path := valuePath(prefix, k)
+		newValue, ok := after[k]
+		if !ok {
+			changes = append(changes, valueChange{action: '-', path: path, old: oldValue})
+			continue
+		}
+		oldMap, oldIsMap := oldValue.(map[string]interface{})
+		newMap, newIsMap := newValue.(map[string]interface{})
+		if oldIsMap && newIsMap {
+			changes = append(changes, diffValues(path, oldMap, newMap)...)
+			continue
+		}
+		if !reflect.DeepEqual(oldValue, newValue) {
+			changes = append(changes, valueChange{action: '~', path: path, old: oldValue, new: newValue})
+		}
+	}
+	for k, newValue := range after {
+		if _, ok := before[k]; !ok {
+			changes = append(changes, valueChange{action: '+', path: valuePath(prefix, k), new: newValue})
+		}
+	}
+	sort.Slice(changes, func(i, j int) bool {
+		return changes[i].path < changes[j].path
+	})
+	return changes
+}
+
+// valuePath appends key to prefix, in the notation of the set attribute
+func valuePath(prefix, key string) string {
+	key = strings.ReplaceAll(key, ".", `\.`)
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+func writeValueChanges(w *strings.Builder, changes []valueChange) {
+	for _, c := range changes {
+		switch c.action {
+		case '+':
+			fmt.Fprintf(w, "  + %s = %s\n", c.path, formatDefaultValue(c.new))
+		case '-':
+			fmt.Fprintf(w, "  - %s\n", c.path)
+		default:
+			fmt.Fprintf(w, "  ~ %s: %s -> %s\n", c.path, formatDefaultValue(c.old), formatDefaultValue(c.new))
+		}
+	}
+}
+
+func formatDefaultValue(v interface{}) string {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	s := string(b)
+	if len(s) > maxDefaultValueLength {
+		s = s[:maxDefaultValueLength] + "..."
+	}
+	return s
+}
+
+// jsonEqual returns whether the JSON documents a and b are equal, ignoring
+// formatting
+func jsonEqual(a, b []byte) bool {
+	if len(a) == 0 || len(b) == 0 {
+		return len(a) == len(b)
+	}
+	var va, vb interface{}
+	if json.Unmarshal(a, &va) != nil || json.Unmarshal(b, &vb) != nil {
+		return string(a) == string(b)
+	}
+	return reflect.DeepEqual(va, vb)
+}
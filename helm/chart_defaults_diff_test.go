@@ -0,0 +1,179 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestDiffValues(t *testing.T) {
+	before := map[string]interface{}{
+		"replicaCount": 1,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.19",
+		},
+		"ingress":     map[string]interface{}{"enabled": false},
+		"tolerations": []interface{}{},
+		"nodeSelector": map[string]interface{}{
+			"kubernetes.io/os": "linux",
+		},
+	}
+	after := map[string]interface{}{
+		"replicaCount": 1,
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.25",
+			"pullPolicy": "Always",
+		},
+		"ingress":     true,
+		"tolerations": []interface{}{map[string]interface{}{"operator": "Exists"}},
+		"nodeSelector": map[string]interface{}{
+			"kubernetes.io/os": "windows",
+		},
+		"podAnnotations": map[string]interface{}{},
+	}
+
+	expected := []valueChange{
+		{action: '+', path: "image.pullPolicy", new: "Always"},
+		{action: '~', path: "image.tag", old: "1.19", new: "1.25"},
+		{action: '~', path: "ingress", old: map[string]interface{}{"enabled": false}, new: true},
+		{action: '~', path: `nodeSelector.kubernetes\.io/os`, old: "linux", new: "windows"},
+		{action: '+', path: "podAnnotations", new: map[string]interface{}{}},
+		{action: '~', path: "tolerations", old: []interface{}{}, new: []interface{}{map[string]interface{}{"operator": "Exists"}}},
+	}
+	if changes := diffValues("", before, after); !reflect.DeepEqual(changes, expected) {
+		t.Errorf("unexpected changes:\n%#v\nexpected:\n%#v", changes, expected)
+	}
+	if changes := diffValues("", before, before); len(changes) != 0 {
+		t.Errorf("expected no changes, got %#v", changes)
+	}
+}
+
+func TestChartDefaultsDiff(t *testing.T) {
+	ctx := context.Background()
+
+	// Serve both versions of the test chart from a chart repository
+	dir := t.TempDir()
+	for _, name := range []string{"test-chart", "test-chart-v2"} {
+		c, err := loader.Load(filepath.Join(testChartsPath, name))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := chartutil.Save(c, dir); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+	defer server.Close()
+	index, err := repo.IndexDirectory(dir, server.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := index.WriteFile(filepath.Join(dir, "index.yaml"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(t.TempDir(), "repositories.yaml")
+	settings.RepositoryCache = t.TempDir()
+	meta := &Meta{Settings: settings}
+
+	newChart, err := loader.Load(filepath.Join(testChartsPath, "test-chart-v2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := &HelmReleaseModel{
+		Chart:      types.StringValue("test-chart"),
+		Repository: types.StringValue(server.URL),
+		Version:    types.StringValue(newChart.Metadata.Version),
+	}
+
+	diags := chartDefaultsDiff(ctx, meta, new(action.Configuration), plan, "1.2.3", newChart)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if len(diags) != 1 || diags[0].Summary() != "Chart default values changed" {
+		t.Fatalf("expected a warning listing the changed defaults, got %v", diags)
+	}
+	for _, expected := range []string{"  - cloakedData\n", "  + set_list_test = []\n"} {
+		if !strings.Contains(diags[0].Detail(), expected) {
+			t.Errorf("expected %q in the warning:\n%s", expected, diags[0].Detail())
+		}
+	}
+
+	diags = chartDefaultsDiff(ctx, meta, new(action.Configuration), plan, "0.0.1", newChart)
+	if len(diags) != 1 || diags[0].Summary() != "Unable to compare chart default values" {
+		t.Fatalf("expected a warning for a missing chart version, got %v", diags)
+	}
+}
+
+func TestDiffChartDefaults(t *testing.T) {
+	subchart := func(version string, values map[string]interface{}, schema string) *chart.Chart {
+		return &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "redis", Version: version},
+			Values:   values,
+			Schema:   []byte(schema),
+		}
+	}
+	parent := func(version string, subcharts ...*chart.Chart) *chart.Chart {
+		c := &chart.Chart{
+			Metadata: &chart.Metadata{APIVersion: chart.APIVersionV2, Name: "app", Version: version},
+			Values:   map[string]interface{}{"replicaCount": 1},
+		}
+		c.SetDependencies(subcharts...)
+		return c
+	}
+
+	oldChart := parent("1.0.0", subchart("18.4.0",
+		map[string]interface{}{"auth": map[string]interface{}{"enabled": false}},
+		`{"properties": {"auth": {"type": "object"}}}`,
+	))
+	newChart := parent("1.1.0", subchart("19.0.0",
+		map[string]interface{}{"auth": map[string]interface{}{"enabled": true}},
+		`{"properties": {"auth": {"type": "object"}, "tls": {"type": "object"}}}`,
+	))
+
+	values, schemas, err := diffChartDefaults(oldChart, newChart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expectedValues := []valueChange{
+		{action: '~', path: "redis.auth.enabled", old: false, new: true},
+	}
+	if !reflect.DeepEqual(values, expectedValues) {
+		t.Errorf("unexpected value changes:\n%#v\nexpected:\n%#v", values, expectedValues)
+	}
+	expectedSchemas := []chartSchemaChanges{
+		{chart: "redis", changes: []valueChange{
+			{action: '+', path: "properties.tls", new: map[string]interface{}{"type": "object"}},
+		}},
+	}
+	if !reflect.DeepEqual(schemas, expectedSchemas) {
+		t.Errorf("unexpected schema changes:\n%#v\nexpected:\n%#v", schemas, expectedSchemas)
+	}
+
+	// Subcharts only part of one of the versions are compared with no schema
+	_, schemas, err = diffChartDefaults(parent("1.0.0"), newChart)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(schemas) != 1 || schemas[0].chart != "redis" || len(schemas[0].changes) != 1 || schemas[0].changes[0].path != "properties" {
+		t.Errorf("expected the schema of the added subchart to be listed, got %#v", schemas)
+	}
+}
@@ -0,0 +1,407 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/provenance"
+)
+
+var (
+	_ datasource.DataSource              = &HelmChartDataSource{}
+	_ datasource.DataSourceWithConfigure = &HelmChartDataSource{}
+)
+
+func NewHelmChartDataSource() datasource.DataSource {
+	return &HelmChartDataSource{}
+}
+
+// HelmChartDataSource resolves a chart version without installing it
+type HelmChartDataSource struct {
+	meta *Meta
+}
+
+// HelmChartDataSourceModel holds the attributes of the helm_chart data source
+type HelmChartDataSourceModel struct {
+	AppVersion         types.String            `tfsdk:"app_version"`
+	Chart              types.String            `tfsdk:"chart"`
+	Dependencies       []HelmChartDependency   `tfsdk:"dependencies"`
+	Devel              types.Bool              `tfsdk:"devel"`
+	Digest             types.String            `tfsdk:"digest"`
+	ID                 types.String            `tfsdk:"id"`
+	Keyring            types.String            `tfsdk:"keyring"`
+	Metadata           *HelmChartMetadataModel `tfsdk:"metadata"`
+	PassCredentials    types.Bool              `tfsdk:"pass_credentials"`
+	Repository         types.String            `tfsdk:"repository"`
+	RepositoryCaFile   types.String            `tfsdk:"repository_ca_file"`
+	RepositoryCertFile types.String            `tfsdk:"repository_cert_file"`
+	RepositoryKeyFile  types.String            `tfsdk:"repository_key_file"`
+	RepositoryPassword types.String            `tfsdk:"repository_password"`
+	RepositoryUsername types.String            `tfsdk:"repository_username"`
+	ResolvedVersion    types.String            `tfsdk:"resolved_version"`
+	Timeouts           timeouts.Value          `tfsdk:"timeouts"`
+	Values             types.String            `tfsdk:"values"`
+	Verify             types.Bool              `tfsdk:"verify"`
+	Version            types.String            `tfsdk:"version"`
+}
+
+// HelmChartMetadataModel holds the metadata of Chart.yaml
+type HelmChartMetadataModel struct {
+	Annotations types.Map    `tfsdk:"annotations"`
+	APIVersion  types.String `tfsdk:"api_version"`
+	Deprecated  types.Bool   `tfsdk:"deprecated"`
+	Description types.String `tfsdk:"description"`
+	Home        types.String `tfsdk:"home"`
+	Icon        types.String `tfsdk:"icon"`
+	Keywords    types.List   `tfsdk:"keywords"`
+	KubeVersion types.String `tfsdk:"kube_version"`
+	Name        types.String `tfsdk:"name"`
+	Sources     types.List   `tfsdk:"sources"`
+	Type        types.String `tfsdk:"type"`
+}
+
+// HelmChartDependency holds a dependency declared in Chart.yaml
+type HelmChartDependency struct {
+	Alias      types.String `tfsdk:"alias"`
+	Condition  types.String `tfsdk:"condition"`
+	Name       types.String `tfsdk:"name"`
+	Repository types.String `tfsdk:"repository"`
+	Version    types.String `tfsdk:"version"`
+}
+
+func (d *HelmChartDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	meta, ok := req.ProviderData.(*Meta)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Provider Configuration Error",
+			fmt.Sprintf("Unexpected ProviderData type: %T", req.ProviderData),
+		)
+		return
+	}
+	d.meta = meta
+}
+
+func (d *HelmChartDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_chart"
+}
+
+func (d *HelmChartDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to resolve the version of a chart and read its metadata without installing it.",
+		Attributes: map[string]schema.Attribute{
+			"app_version": schema.StringAttribute{
+				Computed:    true,
+				Description: "The version of the application packaged by the resolved chart.",
+			},
+			"chart": schema.StringAttribute{
+				Required:    true,
+				Description: "Chart name to be resolved. A path or a `repository/chart` name may be used.",
+			},
+			"dependencies": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "Dependencies declared by the resolved chart.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"alias": schema.StringAttribute{
+							Computed: true,
+						},
+						"condition": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"repository": schema.StringAttribute{
+							Computed: true,
+						},
+						"version": schema.StringAttribute{
+							Computed:    true,
+							Description: "Version or version constraint of the dependency.",
+						},
+					},
+				},
+			},
+			"devel": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Use chart development versions, too. Equivalent to version '>0.0.0-0'. If `version` is set, this is ignored.",
+			},
+			"digest": schema.StringAttribute{
+				Computed:    true,
+				Description: "SHA256 digest of the resolved chart archive. Not set for unpacked local charts.",
+			},
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"keyring": schema.StringAttribute{
+				Optional:    true,
+				Description: "Location of public keys used for verification. Used only if `verify` is true.",
+			},
+			"metadata": schema.SingleNestedAttribute{
+				Computed:    true,
+				Description: "Metadata of the resolved chart, from its Chart.yaml.",
+				Attributes: map[string]schema.Attribute{
+					"annotations": schema.MapAttribute{
+						Computed:    true,
+						ElementType: types.StringType,
+					},
+					"api_version": schema.StringAttribute{
+						Computed: true,
+					},
+					"deprecated": schema.BoolAttribute{
+						Computed: true,
+					},
+					"description": schema.StringAttribute{
+						Computed: true,
+					},
+					"home": schema.StringAttribute{
+						Computed: true,
+					},
+					"icon": schema.StringAttribute{
+						Computed: true,
+					},
+					"keywords": schema.ListAttribute{
+						Computed:    true,
+						ElementType: types.StringType,
+					},
+					"kube_version": schema.StringAttribute{
+						Computed:    true,
+						Description: "Constraint on the Kubernetes versions supported by the chart.",
+					},
+					"name": schema.StringAttribute{
+						Computed: true,
+					},
+					"sources": schema.ListAttribute{
+						Computed:    true,
+						ElementType: types.StringType,
+					},
+					"type": schema.StringAttribute{
+						Computed: true,
+					},
+				},
+			},
+			"pass_credentials": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Pass credentials to all domains",
+			},
+			"repository": schema.StringAttribute{
+				Optional:    true,
+				Description: "Repository where to locate the requested chart. If it is a URL, the chart is resolved without adding the repository.",
+			},
+			"repository_ca_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "The repository's CA file",
+			},
+			"repository_cert_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "The repository's cert file",
+			},
+			"repository_key_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "The repository's cert key file",
+			},
+			"repository_password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password for HTTP basic authentication",
+			},
+			"repository_username": schema.StringAttribute{
+				Optional:    true,
+				Description: "Username for HTTP basic authentication",
+			},
+			"resolved_version": schema.StringAttribute{
+				Computed:    true,
+				Description: "The exact version of the chart matching `version`.",
+			},
+			"timeouts": timeouts.Attributes(ctx),
+			"values": schema.StringAttribute{
+				Computed:    true,
+				Description: "Content of the default values.yaml of the resolved chart.",
+			},
+			"verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Verify the package before resolving it.",
+			},
+			"version": schema.StringAttribute{
+				Optional:    true,
+				Description: "Exact version or semver constraint, such as `~1.4`, to resolve. If this is not specified, the latest version is resolved.",
+			},
+		},
+	}
+}
+
+func (d *HelmChartDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmChartDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	meta := d.meta
+	if meta == nil {
+		resp.Diagnostics.AddError("Initialization Error", "Meta instance is not initialized")
+		return
+	}
+
+	// The chart is only located, the action configuration is not bound to a
+	// cluster and only carries the registry client for OCI charts
+	actionConfig := new(action.Configuration)
+	resp.Diagnostics.Append(OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString())...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	client := action.NewInstall(actionConfig)
+
+	cpo, chartName, cpoDiags := chartDataSourcePathOptions(&state, &client.ChartPathOptions)
+	resp.Diagnostics.Append(cpoDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Resolving chart %s with version %q", chartName, cpo.Version))
+	chartPath, err := cpo.LocateChart(chartName, meta.Settings)
+	if err != nil {
+		resp.Diagnostics.AddError("Error locating chart", fmt.Sprintf("Unable to locate chart %s: %s", chartName, err))
+		return
+	}
+
+	c, err := loader.Load(chartPath)
+	if err != nil {
+		resp.Diagnostics.AddError("Error loading chart", fmt.Sprintf("Unable to load chart %s: %s", chartPath, err))
+		return
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Resolved chart %s to version %s at %s", chartName, c.Metadata.Version, chartPath))
+
+	state.Digest = types.StringNull()
+	if fi, err := os.Stat(chartPath); err == nil && !fi.IsDir() {
+		digest, err := provenance.DigestFile(chartPath)
+		if err != nil {
+			resp.Diagnostics.AddError("Error computing chart digest", fmt.Sprintf("Unable to compute the digest of %s: %s", chartPath, err))
+			return
+		}
+		state.Digest = types.StringValue("sha256:" + digest)
+	}
+
+	state.ID = types.StringValue(fmt.Sprintf("%s:%s", c.Metadata.Name, c.Metadata.Version))
+	state.ResolvedVersion = types.StringValue(c.Metadata.Version)
+	state.AppVersion = types.StringValue(c.Metadata.AppVersion)
+	state.Values = types.StringValue(chartDefaultValues(c))
+
+	state.Metadata, diags = chartMetadataModel(ctx, c.Metadata)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.Dependencies = make([]HelmChartDependency, 0, len(c.Metadata.Dependencies))
+	for _, dep := range c.Metadata.Dependencies {
+		state.Dependencies = append(state.Dependencies, HelmChartDependency{
+			Alias:      optionalString(dep.Alias),
+			Condition:  optionalString(dep.Condition),
+			Name:       types.StringValue(dep.Name),
+			Repository: optionalString(dep.Repository),
+			Version:    optionalString(dep.Version),
+		})
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// chartDataSourcePathOptions configures cpo to locate the chart of the data
+// source, the same way chartPathOptions does for releases
+func chartDataSourcePathOptions(model *HelmChartDataSourceModel, cpo *action.ChartPathOptions) (*action.ChartPathOptions, string, diag.Diagnostics) {
+	version := strings.TrimSpace(model.Version.ValueString())
+	if version == "" && model.Devel.ValueBool() {
+		version = ">0.0.0-0"
+	}
+
+	keyring := model.Keyring.ValueString()
+	if keyring == "" && model.Verify.ValueBool() {
+		keyring = os.ExpandEnv("$HOME/.gnupg/pubring.gpg")
+	}
+
+	return chartLocationPathOptions(chartLocation{
+		chart:           model.Chart.ValueString(),
+		repository:      model.Repository.ValueString(),
+		version:         version,
+		caFile:          model.RepositoryCaFile.ValueString(),
+		certFile:        model.RepositoryCertFile.ValueString(),
+		keyFile:         model.RepositoryKeyFile.ValueString(),
+		keyring:         keyring,
+		username:        model.RepositoryUsername.ValueString(),
+		password:        model.RepositoryPassword.ValueString(),
+		verify:          model.Verify.ValueBool(),
+		passCredentials: model.PassCredentials.ValueBool(),
+	}, cpo)
+}
+
+// chartDefaultValues returns the values.yaml file of the chart as written by
+// its authors, comments included
+func chartDefaultValues(c *chart.Chart) string {
+	for _, f := range c.Raw {
+		if f.Name == chartutil.ValuesfileName {
+			return string(f.Data)
+		}
+	}
+	return ""
+}
+
+func chartMetadataModel(ctx context.Context, m *chart.Metadata) (*HelmChartMetadataModel, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	annotations, d := types.MapValueFrom(ctx, types.StringType, m.Annotations)
+	diags.Append(d...)
+	keywords, d := types.ListValueFrom(ctx, types.StringType, m.Keywords)
+	diags.Append(d...)
+	sources, d := types.ListValueFrom(ctx, types.StringType, m.Sources)
+	diags.Append(d...)
+
+	return &HelmChartMetadataModel{
+		Annotations: annotations,
+		APIVersion:  types.StringValue(m.APIVersion),
+		Deprecated:  types.BoolValue(m.Deprecated),
+		Description: optionalString(m.Description),
+		Home:        optionalString(m.Home),
+		Icon:        optionalString(m.Icon),
+		Keywords:    keywords,
+		KubeVersion: optionalString(m.KubeVersion),
+		Name:        types.StringValue(m.Name),
+		Sources:     sources,
+		Type:        optionalString(m.Type),
+	}, diags
+}
+
+// optionalString returns a null value for the empty string
+func optionalString(s string) types.String {
+	if s == "" {
+		return types.StringNull()
+	}
+	return types.StringValue(s)
+}
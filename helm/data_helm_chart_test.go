@@ -0,0 +1,72 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestAccDataChart_versionConstraint(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataHelmChartConfig("test-chart", "~1.2"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.helm_chart.test", "resolved_version", "1.2.3"),
+					resource.TestCheckResourceAttr("data.helm_chart.test", "app_version", "1.19.5"),
+					resource.TestCheckResourceAttr("data.helm_chart.test", "metadata.name", "test-chart"),
+					resource.TestCheckResourceAttr("data.helm_chart.test", "metadata.api_version", "v2"),
+					resource.TestMatchResourceAttr("data.helm_chart.test", "digest", regexp.MustCompile(`^sha256:[0-9a-f]{64}$`)),
+					resource.TestMatchResourceAttr("data.helm_chart.test", "values", regexp.MustCompile(`replicaCount: 1`)),
+				),
+			},
+			{
+				Config: testAccDataHelmChartConfig("test-chart", ""),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("data.helm_chart.test", "resolved_version", "2.0.0"),
+				),
+			},
+			{
+				Config:      testAccDataHelmChartConfig("test-chart", "~3.0"),
+				ExpectError: regexp.MustCompile("Unable to locate chart"),
+			},
+		},
+	})
+}
+
+func TestAccDataChart_localDependencies(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: `
+				data "helm_chart" "test" {
+					chart = "./testdata/charts/umbrella-chart"
+				}
+			`,
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr("data.helm_chart.test", "resolved_version", "0.1.0"),
+				resource.TestCheckNoResourceAttr("data.helm_chart.test", "digest"),
+				resource.TestCheckResourceAttr("data.helm_chart.test", "dependencies.#", "2"),
+				resource.TestCheckResourceAttr("data.helm_chart.test", "dependencies.0.name", "dependency-foo"),
+				resource.TestCheckResourceAttr("data.helm_chart.test", "dependencies.0.version", "0.x.x"),
+				resource.TestCheckResourceAttr("data.helm_chart.test", "dependencies.0.repository", "file://../dependency-foo"),
+			),
+		}},
+	})
+}
+
+func testAccDataHelmChartConfig(chart, version string) string {
+	return fmt.Sprintf(`
+		data "helm_chart" "test" {
+			repository = %q
+			chart      = %q
+			version    = %q
+		}
+	`, testRepositoryURL, chart, version)
+}
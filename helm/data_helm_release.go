@@ -0,0 +1,288 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+)
+
+var (
+	_ datasource.DataSource              = &HelmReleaseDataSource{}
+	_ datasource.DataSourceWithConfigure = &HelmReleaseDataSource{}
+)
+
+func NewHelmReleaseDataSource() datasource.DataSource {
+	return &HelmReleaseDataSource{}
+}
+
+// HelmReleaseDataSource reads an existing Helm release without managing it
+type HelmReleaseDataSource struct {
+	meta *Meta
+}
+
+// HelmReleaseDataSourceModel holds the attributes of the helm_release data source
+type HelmReleaseDataSourceModel struct {
+	Description     types.String   `tfsdk:"description"`
+	ID              types.String   `tfsdk:"id"`
+	IncludeManifest types.Bool     `tfsdk:"include_manifest"`
+	Manifest        types.String   `tfsdk:"manifest"`
+	Metadata        types.Object   `tfsdk:"metadata"`
+	Name            types.String   `tfsdk:"name"`
+	Namespace       types.String   `tfsdk:"namespace"`
+	SensitiveValues types.List     `tfsdk:"sensitive_values"`
+	Status          types.String   `tfsdk:"status"`
+	Timeouts        timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *HelmReleaseDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	meta, ok := req.ProviderData.(*Meta)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Provider Configuration Error",
+			fmt.Sprintf("Unexpected ProviderData type: %T", req.ProviderData),
+		)
+		return
+	}
+	d.meta = meta
+}
+
+func (d *HelmReleaseDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_release"
+}
+
+func (d *HelmReleaseDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to read an existing Helm release.",
+		Attributes: map[string]schema.Attribute{
+			"description": schema.StringAttribute{
+				Computed:    true,
+				Description: "Description of the current release revision.",
+			},
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"include_manifest": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, the rendered manifest of the release is exposed in `manifest`.",
+			},
+			"manifest": schema.StringAttribute{
+				Computed:    true,
+				Description: "The rendered manifest as JSON. Only set when `include_manifest` is true.",
+			},
+			"metadata": schema.SingleNestedAttribute{
+				Computed:    true,
+				Description: "Status of the deployed release.",
+				Attributes: map[string]schema.Attribute{
+					"app_version": schema.StringAttribute{
+						Computed:    true,
+						Description: "The version number of the application being deployed",
+					},
+					"chart": schema.StringAttribute{
+						Computed:    true,
+						Description: "The name of the chart",
+					},
+					"first_deployed": schema.Int64Attribute{
+						Computed:    true,
+						Description: "FirstDeployed is an int64 which represents timestamp when the release was first deployed.",
+					},
+					"last_deployed": schema.Int64Attribute{
+						Computed:    true,
+						Description: "LastDeployed is an int64 which represents timestamp when the release was last deployed.",
+					},
+					"name": schema.StringAttribute{
+						Computed:    true,
+						Description: "Name is the name of the release",
+					},
+					"namespace": schema.StringAttribute{
+						Computed:    true,
+						Description: "Namespace is the kubernetes namespace of the release",
+					},
+					"notes": schema.StringAttribute{
+						Computed:    true,
+						Description: "Notes is the description of the deployed release, rendered from templates.",
+					},
+					"revision": schema.Int64Attribute{
+						Computed:    true,
+						Description: "Version is an int32 which represents the version of the release",
+					},
+					"values": schema.StringAttribute{
+						Computed:    true,
+						Description: "Set of extra values added to the chart. The paths listed in `sensitive_values` are cloaked. JSON encoded.",
+					},
+					"version": schema.StringAttribute{
+						Computed:    true,
+						Description: "A SemVer 2 conformant version string of the chart",
+					},
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Release name.",
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 53),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Namespace of the release. Defaults to the HELM_NAMESPACE environment variable or 'default'.",
+			},
+			"sensitive_values": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Value paths, in the same notation as `set_sensitive` names, to cloak in `metadata.values`.",
+			},
+			"status": schema.StringAttribute{
+				Computed:    true,
+				Description: "Status of the release.",
+			},
+			"timeouts": timeouts.Attributes(ctx),
+		},
+	}
+}
+
+func (d *HelmReleaseDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmReleaseDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	meta := d.meta
+	if meta == nil {
+		resp.Diagnostics.AddError("Initialization Error", "Meta instance is not initialized")
+		return
+	}
+
+	if state.Namespace.IsNull() || state.Namespace.IsUnknown() {
+		defaultNamespace := os.Getenv("HELM_NAMESPACE")
+		if defaultNamespace == "" {
+			defaultNamespace = "default"
+		}
+		state.Namespace = types.StringValue(defaultNamespace)
+	}
+	name := state.Name.ValueString()
+	namespace := state.Namespace.ValueString()
+
+	logID := fmt.Sprintf("[dataReleaseRead: %s]", name)
+	tflog.Debug(ctx, fmt.Sprintf("%s Started", logID))
+
+	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting helm configuration",
+			fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err),
+		)
+		return
+	}
+
+	rel, err := getRelease(ctx, meta, actionConfig, name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting release",
+			fmt.Sprintf("Unable to get Helm release %s in namespace %s: %s", name, namespace, err),
+		)
+		return
+	}
+
+	// Map the data source onto a release model so the resource's
+	// attribute handling, including value cloaking, can be reused.
+	releaseState := HelmReleaseModel{
+		Name:          state.Name,
+		Namespace:     state.Namespace,
+		Version:       types.StringNull(),
+		SetWORevision: types.Int64Null(),
+	}
+	releaseState.SetSensitive, diags = sensitivePathsToSetList(ctx, state.SensitiveValues)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	diags = setReleaseAttributes(ctx, &releaseState, nil, rel, meta)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	state.ID = types.StringValue(fmt.Sprintf("%s/%s", rel.Namespace, rel.Name))
+	state.Namespace = types.StringValue(rel.Namespace)
+	state.Status = releaseState.Status
+	state.Metadata = releaseState.Metadata
+	state.Description = types.StringValue(rel.Info.Description)
+
+	state.Manifest = types.StringNull()
+	if state.IncludeManifest.ValueBool() {
+		jsonManifest, err := convertYAMLManifestToJSON(rel.Manifest)
+		if err != nil {
+			resp.Diagnostics.AddError(
+				"Error converting manifest to JSON",
+				fmt.Sprintf("Unable to convert manifest to JSON: %s", err),
+			)
+			return
+		}
+		state.Manifest = types.StringValue(jsonManifest)
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("%s Done", logID))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// sensitivePathsToSetList converts a list of value paths into the shape of
+// the set_sensitive attribute so that cloakSetValues can be applied to them.
+func sensitivePathsToSetList(ctx context.Context, paths types.List) (types.List, diag.Diagnostics) {
+	objectType := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name":  types.StringType,
+			"type":  types.StringType,
+			"value": types.StringType,
+		},
+	}
+	if paths.IsNull() || paths.IsUnknown() {
+		return types.ListNull(objectType), nil
+	}
+
+	var names []string
+	diags := paths.ElementsAs(ctx, &names, false)
+	if diags.HasError() {
+		return types.ListNull(objectType), diags
+	}
+
+	sets := make([]setResourceModel, 0, len(names))
+	for _, n := range names {
+		sets = append(sets, setResourceModel{
+			Name:  types.StringValue(n),
+			Type:  types.StringNull(),
+			Value: types.StringNull(),
+		})
+	}
+	list, listDiags := types.ListValueFrom(ctx, objectType, sets)
+	diags.Append(listDiags...)
+	return list, diags
+}
@@ -0,0 +1,303 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+var (
+	_ datasource.DataSource              = &HelmReleaseHistoryDataSource{}
+	_ datasource.DataSourceWithConfigure = &HelmReleaseHistoryDataSource{}
+)
+
+func NewHelmReleaseHistoryDataSource() datasource.DataSource {
+	return &HelmReleaseHistoryDataSource{}
+}
+
+// HelmReleaseHistoryDataSource reads the revisions of a release kept in Helm storage
+type HelmReleaseHistoryDataSource struct {
+	meta *Meta
+}
+
+// HelmReleaseHistoryDataSourceModel holds the attributes of the helm_release_history data source
+type HelmReleaseHistoryDataSourceModel struct {
+	ID              types.String   `tfsdk:"id"`
+	MaxHistory      types.Int64    `tfsdk:"max_history"`
+	Name            types.String   `tfsdk:"name"`
+	Namespace       types.String   `tfsdk:"namespace"`
+	Revisions       types.List     `tfsdk:"revisions"`
+	SensitiveValues types.List     `tfsdk:"sensitive_values"`
+	Timeouts        timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *HelmReleaseHistoryDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	meta, ok := req.ProviderData.(*Meta)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Provider Configuration Error",
+			fmt.Sprintf("Unexpected ProviderData type: %T", req.ProviderData),
+		)
+		return
+	}
+	d.meta = meta
+}
+
+func (d *HelmReleaseHistoryDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_release_history"
+}
+
+func (d *HelmReleaseHistoryDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to read the revision history of a Helm release.",
+		Attributes: map[string]schema.Attribute{
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"max_history": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Only return the most recent revisions, up to this number. Defaults to 0 (all stored revisions).",
+				Validators: []validator.Int64{
+					int64validator.AtLeast(0),
+				},
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Release name.",
+				Validators: []validator.String{
+					stringvalidator.LengthBetween(1, 53),
+				},
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Namespace of the release. Defaults to the HELM_NAMESPACE environment variable or 'default'.",
+			},
+			"revisions": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The stored revisions of the release, oldest first.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"app_version": schema.StringAttribute{
+							Computed:    true,
+							Description: "The version number of the application deployed by this revision",
+						},
+						"chart": schema.StringAttribute{
+							Computed:    true,
+							Description: "The name of the chart",
+						},
+						"deleted": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Timestamp when the revision was uninstalled, 0 if it was not.",
+						},
+						"description": schema.StringAttribute{
+							Computed:    true,
+							Description: "Description of the revision",
+						},
+						"first_deployed": schema.Int64Attribute{
+							Computed:    true,
+							Description: "FirstDeployed is an int64 which represents timestamp when the release was first deployed.",
+						},
+						"last_deployed": schema.Int64Attribute{
+							Computed:    true,
+							Description: "LastDeployed is an int64 which represents timestamp when the revision was deployed.",
+						},
+						"revision": schema.Int64Attribute{
+							Computed:    true,
+							Description: "The revision number",
+						},
+						"status": schema.StringAttribute{
+							Computed:    true,
+							Description: "Status of the revision",
+						},
+						"values": schema.StringAttribute{
+							Computed:    true,
+							Description: "Set of extra values added to the chart. The paths listed in `sensitive_values` are cloaked. JSON encoded.",
+						},
+						"version": schema.StringAttribute{
+							Computed:    true,
+							Description: "A SemVer 2 conformant version string of the chart",
+						},
+					},
+				},
+			},
+			"sensitive_values": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Value paths, in the same notation as `set_sensitive` names, to cloak in the `values` of every revision.",
+			},
+			"timeouts": timeouts.Attributes(ctx),
+		},
+	}
+}
+
+func (d *HelmReleaseHistoryDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmReleaseHistoryDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	meta := d.meta
+	if meta == nil {
+		resp.Diagnostics.AddError("Initialization Error", "Meta instance is not initialized")
+		return
+	}
+
+	if state.Namespace.IsNull() || state.Namespace.IsUnknown() {
+		defaultNamespace := os.Getenv("HELM_NAMESPACE")
+		if defaultNamespace == "" {
+			defaultNamespace = "default"
+		}
+		state.Namespace = types.StringValue(defaultNamespace)
+	}
+	name := state.Name.ValueString()
+	namespace := state.Namespace.ValueString()
+
+	logID := fmt.Sprintf("[dataReleaseHistoryRead: %s]", name)
+	tflog.Debug(ctx, fmt.Sprintf("%s Started", logID))
+
+	var sensitivePaths []string
+	if !state.SensitiveValues.IsNull() && !state.SensitiveValues.IsUnknown() {
+		resp.Diagnostics.Append(state.SensitiveValues.ElementsAs(ctx, &sensitivePaths, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting helm configuration",
+			fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err),
+		)
+		return
+	}
+
+	// Revisions pruned from storage because of max_history on the
+	// helm_release resource are no longer returned by the History action.
+	history := action.NewHistory(actionConfig)
+	revisions, err := history.Run(name)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting release history",
+			fmt.Sprintf("Unable to get the history of Helm release %s in namespace %s: %s", name, namespace, err),
+		)
+		return
+	}
+	revisions = limitReleaseHistory(revisions, int(state.MaxHistory.ValueInt64()))
+	tflog.Debug(ctx, fmt.Sprintf("%s Found %d revisions", logID, len(revisions)))
+
+	elementType := types.ObjectType{AttrTypes: releaseHistoryAttrTypes()}
+	elements := make([]attr.Value, 0, len(revisions))
+	for _, r := range revisions {
+		values := "{}"
+		if r.Config != nil {
+			configClone := deepCloneMap(r.Config)
+			for _, p := range sensitivePaths {
+				cloakSetValue(configClone, p)
+			}
+			v, err := json.Marshal(configClone)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error marshaling values",
+					fmt.Sprintf("Unable to marshal values of revision %d: %s", r.Version, err),
+				)
+				return
+			}
+			values = string(v)
+		}
+
+		obj, diags := types.ObjectValue(elementType.AttrTypes, releaseHistoryAttributes(r, values))
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		elements = append(elements, obj)
+	}
+
+	state.Revisions, diags = types.ListValue(elementType, elements)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	state.ID = types.StringValue(fmt.Sprintf("%s/%s", namespace, name))
+
+	tflog.Debug(ctx, fmt.Sprintf("%s Done", logID))
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// limitReleaseHistory sorts revisions oldest first and keeps the last max of
+// them, the same way `helm history --max` does. A max of 0 keeps them all.
+func limitReleaseHistory(revisions []*release.Release, max int) []*release.Release {
+	releaseutil.SortByRevision(revisions)
+	if max > 0 && len(revisions) > max {
+		revisions = revisions[len(revisions)-max:]
+	}
+	return revisions
+}
+
+// releaseHistoryAttributes returns the attributes of a revision object
+func releaseHistoryAttributes(r *release.Release, values string) map[string]attr.Value {
+	var deleted int64
+	if !r.Info.Deleted.IsZero() {
+		deleted = r.Info.Deleted.Unix()
+	}
+	return map[string]attr.Value{
+		"app_version":    types.StringValue(r.Chart.Metadata.AppVersion),
+		"chart":          types.StringValue(r.Chart.Metadata.Name),
+		"deleted":        types.Int64Value(deleted),
+		"description":    types.StringValue(r.Info.Description),
+		"first_deployed": types.Int64Value(r.Info.FirstDeployed.Unix()),
+		"last_deployed":  types.Int64Value(r.Info.LastDeployed.Unix()),
+		"revision":       types.Int64Value(int64(r.Version)),
+		"status":         types.StringValue(r.Info.Status.String()),
+		"values":         types.StringValue(values),
+		"version":        types.StringValue(r.Chart.Metadata.Version),
+	}
+}
+
+func releaseHistoryAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"app_version":    types.StringType,
+		"chart":          types.StringType,
+		"deleted":        types.Int64Type,
+		"description":    types.StringType,
+		"first_deployed": types.Int64Type,
+		"last_deployed":  types.Int64Type,
+		"revision":       types.Int64Type,
+		"status":         types.StringType,
+		"values":         types.StringType,
+		"version":        types.StringType,
+	}
+}
@@ -0,0 +1,86 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestAccDataReleaseHistory_upgrade(t *testing.T) {
+	name := randName("data-history")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	datasourceAddress := fmt.Sprintf("data.helm_release_history.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigBasic(testResourceName, namespace, name, "1.2.3"),
+			},
+			{
+				Config: testAccDataHelmReleaseHistoryConfig(testResourceName, namespace, name, "2.0.0", 0),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(datasourceAddress, "id", fmt.Sprintf("%s/%s", namespace, name)),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.#", "2"),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.0.revision", "1"),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.0.status", release.StatusSuperseded.String()),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.0.version", "1.2.3"),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.0.values", `{"fizz":1337,"foo":"(sensitive value)"}`),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.1.revision", "2"),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.1.status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.1.version", "2.0.0"),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.1.deleted", "0"),
+				),
+			},
+			{
+				Config: testAccDataHelmReleaseHistoryConfig(testResourceName, namespace, name, "2.0.0", 1),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.#", "1"),
+					resource.TestCheckResourceAttr(datasourceAddress, "revisions.0.revision", "2"),
+				),
+			},
+		},
+	})
+}
+
+func TestLimitReleaseHistory(t *testing.T) {
+	revisions := []*release.Release{{Version: 3}, {Version: 1}, {Version: 2}}
+
+	all := limitReleaseHistory(revisions, 0)
+	if len(all) != 3 || all[0].Version != 1 || all[2].Version != 3 {
+		t.Fatalf("expected all revisions sorted oldest first, got %v", revisionNumbers(all))
+	}
+
+	latest := limitReleaseHistory(revisions, 2)
+	if len(latest) != 2 || latest[0].Version != 2 || latest[1].Version != 3 {
+		t.Fatalf("expected the 2 most recent revisions, got %v", revisionNumbers(latest))
+	}
+}
+
+func revisionNumbers(revisions []*release.Release) []int {
+	numbers := make([]int, 0, len(revisions))
+	for _, r := range revisions {
+		numbers = append(numbers, r.Version)
+	}
+	return numbers
+}
+
+func testAccDataHelmReleaseHistoryConfig(resource, ns, name, version string, maxHistory int) string {
+	return fmt.Sprintf(`
+		%s
+
+		data "helm_release_history" "%s" {
+			name             = helm_release.%[2]s.name
+			namespace        = helm_release.%[2]s.namespace
+			max_history      = %d
+			sensitive_values = ["foo"]
+		}
+	`, testAccHelmReleaseConfigBasic(resource, ns, name, version), resource, maxHistory)
+}
@@ -0,0 +1,70 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestAccDataRelease_basic(t *testing.T) {
+	name := randName("data-release")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	datasourceAddress := fmt.Sprintf("data.helm_release.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: testAccDataHelmReleaseConfigBasic(testResourceName, namespace, name, "1.2.3"),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr(datasourceAddress, "id", fmt.Sprintf("%s/%s", namespace, name)),
+				resource.TestCheckResourceAttr(datasourceAddress, "status", release.StatusDeployed.String()),
+				resource.TestCheckResourceAttr(datasourceAddress, "metadata.revision", "1"),
+				resource.TestCheckResourceAttr(datasourceAddress, "metadata.chart", "test-chart"),
+				resource.TestCheckResourceAttr(datasourceAddress, "metadata.version", "1.2.3"),
+				resource.TestCheckResourceAttr(datasourceAddress, "metadata.app_version", "1.19.5"),
+				resource.TestCheckResourceAttr(datasourceAddress, "metadata.values", `{"fizz":1337,"foo":"(sensitive value)"}`),
+				resource.TestCheckResourceAttrSet(datasourceAddress, "metadata.notes"),
+				resource.TestCheckResourceAttrSet(datasourceAddress, "manifest"),
+			),
+		}},
+	})
+}
+
+func TestAccDataRelease_notFound(t *testing.T) {
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: fmt.Sprintf(`
+				data "helm_release" "%s" {
+					name      = "does-not-exist"
+					namespace = %q
+				}
+			`, testResourceName, namespace),
+			ExpectError: regexp.MustCompile("Error getting release"),
+		}},
+	})
+}
+
+func testAccDataHelmReleaseConfigBasic(resource, ns, name, version string) string {
+	return fmt.Sprintf(`
+		%s
+
+		data "helm_release" "%s" {
+			name             = helm_release.%[2]s.name
+			namespace        = helm_release.%[2]s.namespace
+			include_manifest = true
+			sensitive_values = ["foo"]
+		}
+	`, testAccHelmReleaseConfigBasic(resource, ns, name, version), resource)
+}
@@ -0,0 +1,342 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"regexp"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/boolvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+var (
+	_ datasource.DataSource              = &HelmReleasesDataSource{}
+	_ datasource.DataSourceWithConfigure = &HelmReleasesDataSource{}
+)
+
+// releaseListStatuses are the release statuses accepted by the helm_releases data source
+var releaseListStatuses = []string{
+	release.StatusDeployed.String(),
+	release.StatusFailed.String(),
+	release.StatusPendingInstall.String(),
+	release.StatusPendingUpgrade.String(),
+	release.StatusPendingRollback.String(),
+	release.StatusSuperseded.String(),
+	release.StatusUninstalling.String(),
+	release.StatusUninstalled.String(),
+}
+
+func NewHelmReleasesDataSource() datasource.DataSource {
+	return &HelmReleasesDataSource{}
+}
+
+// HelmReleasesDataSource lists the Helm releases matching a set of filters
+type HelmReleasesDataSource struct {
+	meta *Meta
+}
+
+// HelmReleasesDataSourceModel holds the attributes of the helm_releases data source
+type HelmReleasesDataSourceModel struct {
+	AllNamespaces   types.Bool     `tfsdk:"all_namespaces"`
+	Filter          types.String   `tfsdk:"filter"`
+	ID              types.String   `tfsdk:"id"`
+	Namespace       types.String   `tfsdk:"namespace"`
+	Releases        types.List     `tfsdk:"releases"`
+	Selector        types.String   `tfsdk:"selector"`
+	SensitiveValues types.List     `tfsdk:"sensitive_values"`
+	Statuses        types.List     `tfsdk:"statuses"`
+	Timeouts        timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (d *HelmReleasesDataSource) Configure(ctx context.Context, req datasource.ConfigureRequest, resp *datasource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	meta, ok := req.ProviderData.(*Meta)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Provider Configuration Error",
+			fmt.Sprintf("Unexpected ProviderData type: %T", req.ProviderData),
+		)
+		return
+	}
+	d.meta = meta
+}
+
+func (d *HelmReleasesDataSource) Metadata(ctx context.Context, req datasource.MetadataRequest, resp *datasource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_releases"
+}
+
+func (d *HelmReleasesDataSource) Schema(ctx context.Context, req datasource.SchemaRequest, resp *datasource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Data source to list the Helm releases matching a set of filters.",
+		Attributes: map[string]schema.Attribute{
+			"all_namespaces": schema.BoolAttribute{
+				Optional:    true,
+				Description: "List releases across all namespaces.",
+				Validators: []validator.Bool{
+					boolvalidator.ConflictsWith(path.MatchRoot("namespace")),
+				},
+			},
+			"filter": schema.StringAttribute{
+				Optional:    true,
+				Description: "A regular expression. Only releases whose name matches the expression are listed.",
+			},
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Namespace to list releases from. Defaults to the HELM_NAMESPACE environment variable or 'default'.",
+			},
+			"releases": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The releases matching the filters, sorted by name.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"app_version": schema.StringAttribute{
+							Computed:    true,
+							Description: "The version number of the application being deployed",
+						},
+						"chart": schema.StringAttribute{
+							Computed:    true,
+							Description: "The name of the chart",
+						},
+						"first_deployed": schema.Int64Attribute{
+							Computed:    true,
+							Description: "FirstDeployed is an int64 which represents timestamp when the release was first deployed.",
+						},
+						"last_deployed": schema.Int64Attribute{
+							Computed:    true,
+							Description: "LastDeployed is an int64 which represents timestamp when the release was last deployed.",
+						},
+						"name": schema.StringAttribute{
+							Computed:    true,
+							Description: "Name is the name of the release",
+						},
+						"namespace": schema.StringAttribute{
+							Computed:    true,
+							Description: "Namespace is the kubernetes namespace of the release",
+						},
+						"notes": schema.StringAttribute{
+							Computed:    true,
+							Description: "Notes is the description of the deployed release, rendered from templates.",
+						},
+						"revision": schema.Int64Attribute{
+							Computed:    true,
+							Description: "Version is an int32 which represents the version of the release",
+						},
+						"status": schema.StringAttribute{
+							Computed:    true,
+							Description: "Status of the release",
+						},
+						"values": schema.StringAttribute{
+							Computed:    true,
+							Description: "Set of extra values added to the chart. The paths listed in `sensitive_values` are cloaked. JSON encoded.",
+						},
+						"version": schema.StringAttribute{
+							Computed:    true,
+							Description: "A SemVer 2 conformant version string of the chart",
+						},
+					},
+				},
+			},
+			"selector": schema.StringAttribute{
+				Optional:    true,
+				Description: "Label selector to filter on the labels of the release storage objects, e.g. `owner=helm,name=foo`.",
+			},
+			"sensitive_values": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Value paths, in the same notation as `set_sensitive` names, to cloak in the `values` of every release.",
+			},
+			"statuses": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Only list releases with one of these statuses. Defaults to `deployed` and `failed`.",
+				Validators: []validator.List{
+					listvalidator.ValueStringsAre(stringvalidator.OneOf(releaseListStatuses...)),
+				},
+			},
+			"timeouts": timeouts.Attributes(ctx),
+		},
+	}
+}
+
+func (d *HelmReleasesDataSource) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
+	var state HelmReleasesDataSourceModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	readTimeout, diags := state.Timeouts.Read(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	ctx, cancel := context.WithTimeout(ctx, readTimeout)
+	defer cancel()
+
+	meta := d.meta
+	if meta == nil {
+		resp.Diagnostics.AddError("Initialization Error", "Meta instance is not initialized")
+		return
+	}
+
+	allNamespaces := state.AllNamespaces.ValueBool()
+	// An empty namespace makes the Helm storage driver look across all namespaces
+	namespace := ""
+	if !allNamespaces {
+		if state.Namespace.IsNull() || state.Namespace.IsUnknown() {
+			namespace = os.Getenv("HELM_NAMESPACE")
+			if namespace == "" {
+				namespace = "default"
+			}
+		} else {
+			namespace = state.Namespace.ValueString()
+		}
+		state.Namespace = types.StringValue(namespace)
+		state.ID = types.StringValue(namespace)
+	} else {
+		state.Namespace = types.StringNull()
+		state.ID = types.StringValue("all-namespaces")
+	}
+
+	filter := state.Filter.ValueString()
+	if filter != "" {
+		if _, err := regexp.Compile(filter); err != nil {
+			resp.Diagnostics.AddAttributeError(
+				path.Root("filter"),
+				"Invalid filter",
+				fmt.Sprintf("Unable to compile filter %q: %s", filter, err),
+			)
+			return
+		}
+	}
+	selector := state.Selector.ValueString()
+	if _, err := labels.Parse(selector); err != nil {
+		resp.Diagnostics.AddAttributeError(
+			path.Root("selector"),
+			"Invalid selector",
+			fmt.Sprintf("Unable to parse selector %q: %s", selector, err),
+		)
+		return
+	}
+
+	var sensitivePaths []string
+	if !state.SensitiveValues.IsNull() && !state.SensitiveValues.IsUnknown() {
+		resp.Diagnostics.Append(state.SensitiveValues.ElementsAs(ctx, &sensitivePaths, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	actionConfig, err := meta.GetHelmConfiguration(ctx, namespace)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error getting helm configuration",
+			fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err),
+		)
+		return
+	}
+
+	listAction := action.NewList(actionConfig)
+	listAction.AllNamespaces = allNamespaces
+	listAction.Filter = filter
+	listAction.Selector = selector
+	listAction.StateMask = action.ListDeployed | action.ListFailed
+	if !state.Statuses.IsNull() && !state.Statuses.IsUnknown() {
+		var statuses []string
+		resp.Diagnostics.Append(state.Statuses.ElementsAs(ctx, &statuses, false)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		if len(statuses) > 0 {
+			listAction.StateMask = releaseListStateMask(statuses)
+		}
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("[dataReleasesRead] Listing releases in namespace %q (all namespaces: %t)", namespace, allNamespaces))
+	releases, err := listAction.Run()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error listing releases",
+			fmt.Sprintf("Unable to list Helm releases: %s", err),
+		)
+		return
+	}
+
+	elementType := types.ObjectType{AttrTypes: releaseListAttrTypes()}
+	elements := make([]attr.Value, 0, len(releases))
+	for _, r := range releases {
+		values := "{}"
+		if r.Config != nil {
+			configClone := deepCloneMap(r.Config)
+			for _, p := range sensitivePaths {
+				cloakSetValue(configClone, p)
+			}
+			v, err := json.Marshal(configClone)
+			if err != nil {
+				resp.Diagnostics.AddError(
+					"Error marshaling values",
+					fmt.Sprintf("Unable to marshal values of release %s: %s", r.Name, err),
+				)
+				return
+			}
+			values = string(v)
+		}
+
+		attrs := releaseMetadataAttributes(r, types.StringValue(values))
+		attrs["status"] = types.StringValue(r.Info.Status.String())
+		obj, diags := types.ObjectValue(elementType.AttrTypes, attrs)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		elements = append(elements, obj)
+	}
+
+	state.Releases, diags = types.ListValue(elementType, elements)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// releaseListStateMask converts release status names into an action.List state mask
+func releaseListStateMask(statuses []string) action.ListStates {
+	var mask action.ListStates
+	for _, s := range statuses {
+		mask |= mask.FromName(s)
+	}
+	return mask
+}
+
+func releaseListAttrTypes() map[string]attr.Type {
+	attrTypes := metadataAttrTypes()
+	attrTypes["status"] = types.StringType
+	return attrTypes
+}
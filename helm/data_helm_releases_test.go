@@ -0,0 +1,109 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+func TestAccDataReleases_basic(t *testing.T) {
+	name := randName("data-releases")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	datasourceAddress := fmt.Sprintf("data.helm_releases.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: testAccDataHelmReleasesConfigBasic(testResourceName, namespace, name, "1.2.3"),
+			Check: resource.ComposeAggregateTestCheckFunc(
+				resource.TestCheckResourceAttr(datasourceAddress, "id", namespace),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.#", "1"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.name", name),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.namespace", namespace),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.status", release.StatusDeployed.String()),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.revision", "1"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.chart", "test-chart"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.version", "1.2.3"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.app_version", "1.19.5"),
+				resource.TestCheckResourceAttr(datasourceAddress, "releases.0.values", `{"fizz":1337,"foo":"(sensitive value)"}`),
+			),
+		}},
+	})
+}
+
+func TestAccDataReleases_filterNoMatch(t *testing.T) {
+	name := randName("data-releases")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	datasourceAddress := fmt.Sprintf("data.helm_releases.%s", testResourceName)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: fmt.Sprintf(`
+				%s
+
+				data "helm_releases" "%s" {
+					namespace = helm_release.%[2]s.namespace
+					filter    = "^does-not-exist$"
+				}
+			`, testAccHelmReleaseConfigBasic(testResourceName, namespace, name, "1.2.3"), testResourceName),
+			Check: resource.TestCheckResourceAttr(datasourceAddress, "releases.#", "0"),
+		}},
+	})
+}
+
+func TestAccDataReleases_invalidFilter(t *testing.T) {
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{{
+			Config: fmt.Sprintf(`
+				data "helm_releases" "%s" {
+					filter = "("
+				}
+			`, testResourceName),
+			ExpectError: regexp.MustCompile("Invalid filter"),
+		}},
+	})
+}
+
+func TestReleaseListStateMask(t *testing.T) {
+	tests := []struct {
+		statuses []string
+		expected action.ListStates
+	}{
+		{[]string{"deployed"}, action.ListDeployed},
+		{[]string{"deployed", "failed"}, action.ListDeployed | action.ListFailed},
+		{[]string{"pending-install", "pending-upgrade", "pending-rollback"}, action.ListPendingInstall | action.ListPendingUpgrade | action.ListPendingRollback},
+		{[]string{"superseded", "uninstalled", "uninstalling"}, action.ListSuperseded | action.ListUninstalled | action.ListUninstalling},
+	}
+
+	for _, tt := range tests {
+		if got := releaseListStateMask(tt.statuses); got != tt.expected {
+			t.Errorf("releaseListStateMask(%v) = %v, expected %v", tt.statuses, got, tt.expected)
+		}
+	}
+}
+
+func testAccDataHelmReleasesConfigBasic(resource, ns, name, version string) string {
+	return fmt.Sprintf(`
+		%s
+
+		data "helm_releases" "%s" {
+			namespace        = helm_release.%[2]s.namespace
+			filter           = "^${helm_release.%[2]s.name}$"
+			statuses         = ["deployed"]
+			sensitive_values = ["foo"]
+		}
+	`, testAccHelmReleaseConfigBasic(resource, ns, name, version), resource)
+}
@@ -18,11 +18,14 @@ import (
 	"time"
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/datasource/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/datasource/schema"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/types"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
@@ -65,11 +68,13 @@ type HelmTemplateModel struct {
 	Devel                    types.Bool       `tfsdk:"devel"`
 	DisableOpenAPIValidation types.Bool       `tfsdk:"disable_openapi_validation"`
 	DisableWebhooks          types.Bool       `tfsdk:"disable_webhooks"`
+	EffectiveValues          types.String     `tfsdk:"effective_values"`
 	ID                       types.String     `tfsdk:"id"`
 	IncludeCRDs              types.Bool       `tfsdk:"include_crds"`
 	IsUpgrade                types.Bool       `tfsdk:"is_upgrade"`
 	Keyring                  types.String     `tfsdk:"keyring"`
 	KubeVersion              types.String     `tfsdk:"kube_version"`
+	ListMerge                *ListMergeModel  `tfsdk:"list_merge"`
 	Manifest                 types.String     `tfsdk:"manifest"`
 	Manifests                types.Map        `tfsdk:"manifests"`
 	Name                     types.String     `tfsdk:"name"`
@@ -95,14 +100,21 @@ type HelmTemplateModel struct {
 	SkipCrds                 types.Bool       `tfsdk:"skip_crds"`
 	SkipTests                types.Bool       `tfsdk:"skip_tests"`
 	Timeout                  types.Int64      `tfsdk:"timeout"`
-	Timeouts                 timeouts.Value   `tfsdk:"timeouts"`
 	Validate                 types.Bool       `tfsdk:"validate"`
 	Values                   types.List       `tfsdk:"values"`
+	ValuesFiles              types.List       `tfsdk:"values_files"`
+	ValuesFilesSHA256        types.Map        `tfsdk:"values_files_sha256"`
 	Version                  types.String     `tfsdk:"version"`
 	Verify                   types.Bool       `tfsdk:"verify"`
 	Wait                     types.Bool       `tfsdk:"wait"`
 }
 
+// HelmTemplateDataSourceModel holds the attributes of the helm_template data source
+type HelmTemplateDataSourceModel struct {
+	HelmTemplateModel
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
 // SetValue represents the custom value to be merged with the Helm chart values
 type SetValue struct {
 	Name  types.String `tfsdk:"name"`
@@ -184,6 +196,10 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Optional:    true,
 				Description: "Prevent hooks from running.",
 			},
+			"effective_values": schema.StringAttribute{
+				Computed:    true,
+				Description: "The values the chart was rendered with as JSON: the default values of the chart and its subcharts coalesced with the values. Sensitive values are cloaked.",
+			},
 			"id": schema.StringAttribute{
 				Computed: true,
 			},
@@ -203,6 +219,23 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				Optional:    true,
 				Description: "Kubernetes version used for Capabilities.KubeVersion.",
 			},
+			"list_merge": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "How the lists of the values files, values documents and set_list are merged. By default, lists are replaced.",
+				Attributes: map[string]schema.Attribute{
+					"key": schema.StringAttribute{
+						Optional:    true,
+						Description: "Key identifying the items of the lists with the merge_by_key strategy. Defaults to `name`.",
+					},
+					"strategy": schema.StringAttribute{
+						Required:    true,
+						Description: "One of `replace`, `append` or `merge_by_key`.",
+						Validators: []validator.String{
+							stringvalidator.OneOf(listMergeStrategies...),
+						},
+					},
+				},
+			},
 			"manifest": schema.StringAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -241,8 +274,83 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 						ElementType: types.StringType,
 					},
 					"binary_path": schema.StringAttribute{
-						Required:    true,
+						Optional:    true,
 						Description: "The common binary path",
+						Validators: []validator.String{
+							stringvalidator.ExactlyOneOf(
+								path.MatchRelative().AtParent().AtName("kustomize"),
+								path.MatchRelative().AtParent().AtName("steps"),
+							),
+						},
+					},
+					"env": schema.MapAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+						Description: "Environment variables added to the environment of the post-renderer command",
+					},
+					"env_sensitive": schema.MapAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						ElementType: types.StringType,
+						Description: "Sensitive environment variables added to the environment of the post-renderer command. They are redacted from logs and error messages",
+					},
+					"kustomize": kustomizePostRenderDataSourceSchema(),
+					"steps": schema.ListNestedAttribute{
+						Optional:    true,
+						Description: "Post-renderers run in order, each one receiving the output of the previous one",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"args": schema.ListAttribute{
+									Optional:    true,
+									Description: "An argument to the post-renderer (can specify multiple)",
+									ElementType: types.StringType,
+								},
+								"binary_path": schema.StringAttribute{
+									Optional:    true,
+									Description: "The command binary path",
+									Validators: []validator.String{
+										stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("kustomize")),
+									},
+								},
+								"env": schema.MapAttribute{
+									Optional:    true,
+									ElementType: types.StringType,
+									Description: "Environment variables added to the environment of the post-renderer command",
+								},
+								"env_sensitive": schema.MapAttribute{
+									Optional:    true,
+									Sensitive:   true,
+									ElementType: types.StringType,
+									Description: "Sensitive environment variables added to the environment of the post-renderer command. They are redacted from logs and error messages",
+								},
+								"kustomize": kustomizePostRenderDataSourceSchema(),
+								"timeout": schema.Int64Attribute{
+									Optional:    true,
+									Description: "Time in seconds after which the post-renderer command is killed",
+									Validators: []validator.Int64{
+										int64validator.AtLeast(1),
+									},
+								},
+								"working_dir": schema.StringAttribute{
+									Optional:    true,
+									Description: "Working directory of the post-renderer command. Relative binary paths are resolved from it",
+								},
+							},
+						},
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+					"timeout": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Time in seconds after which the post-renderer command is killed",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"working_dir": schema.StringAttribute{
+						Optional:    true,
+						Description: "Working directory of the post-renderer command. Relative binary paths are resolved from it",
 					},
 				},
 			},
@@ -391,6 +499,16 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 				ElementType: types.StringType,
 				Description: "List of values in raw yaml format to pass to helm.",
 			},
+			"values_files": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "List of values files to pass to helm, merged before `values`. Accepts local paths, URLs supported by the helm getters and files of the chart.",
+			},
+			"values_files_sha256": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "SHA256 checksums of the content of the values files, by name.",
+			},
 			"verify": schema.BoolAttribute{
 				Optional:    true,
 				Description: "Verify the package before installing it.",
@@ -408,9 +526,97 @@ func (d *HelmTemplate) Schema(ctx context.Context, req datasource.SchemaRequest,
 	}
 }
 
+// kustomizePostRenderDataSourceSchema returns the schema of the built-in kustomize post-renderer,
+// shared by the postrender block and its steps.
+func kustomizePostRenderDataSourceSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:    true,
+		Description: kustomizeDescription,
+		Attributes: map[string]schema.Attribute{
+			"common_annotations": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: kustomizeCommonAnnotationsDescription,
+			},
+			"common_labels": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: kustomizeCommonLabelsDescription,
+			},
+			"images": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: kustomizeImagesDescription,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"digest": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageDigestDescription,
+						},
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: kustomizeImageNameDescription,
+						},
+						"new_name": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageNewNameDescription,
+						},
+						"new_tag": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageNewTagDescription,
+						},
+					},
+				},
+			},
+			"kustomization": schema.StringAttribute{
+				Optional:    true,
+				Description: kustomizeKustomizationDescription,
+			},
+			"patches": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: kustomizePatchesDescription,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"patch": schema.StringAttribute{
+							Required:    true,
+							Description: kustomizePatchDescription,
+						},
+						"target": schema.SingleNestedAttribute{
+							Optional:    true,
+							Description: kustomizePatchTargetDescription,
+							Attributes: map[string]schema.Attribute{
+								"annotation_selector": schema.StringAttribute{
+									Optional: true,
+								},
+								"group": schema.StringAttribute{
+									Optional: true,
+								},
+								"kind": schema.StringAttribute{
+									Optional: true,
+								},
+								"label_selector": schema.StringAttribute{
+									Optional: true,
+								},
+								"name": schema.StringAttribute{
+									Optional: true,
+								},
+								"namespace": schema.StringAttribute{
+									Optional: true,
+								},
+								"version": schema.StringAttribute{
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 // Reads the current state of the data template and will update the state with the data fetched
 func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, resp *datasource.ReadResponse) {
-	var state HelmTemplateModel
+	var state HelmTemplateDataSourceModel
 	resp.Diagnostics.Append(req.Config.Get(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -425,6 +631,19 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	ctx, cancel := context.WithTimeout(ctx, readTimeout)
 	defer cancel()
 
+	resp.Diagnostics.Append(renderTemplate(ctx, d.meta, &state.HelmTemplateModel)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+// renderTemplate renders the chart configured in state with a dry-run
+// install and sets the computed attributes of state from the result.
+func renderTemplate(ctx context.Context, meta *Meta, state *HelmTemplateModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+
 	// setting default values to false is attributes are not provided in the config
 	if state.Description.IsNull() || state.Description.ValueString() == "" {
 		state.Description = types.StringValue("")
@@ -501,15 +720,12 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 		state.Namespace = types.StringValue(defaultNamespace)
 	}
 
-	meta := d.meta
-
 	var apiVersions []string
 	if !state.APIVersions.IsNull() && !state.APIVersions.IsUnknown() {
 		var apiVersionElements []types.String
-		diags := state.APIVersions.ElementsAs(ctx, &apiVersionElements, false)
-		resp.Diagnostics.Append(diags...)
+		diags.Append(state.APIVersions.ElementsAs(ctx, &apiVersionElements, false)...)
 		if diags.HasError() {
-			return
+			return diags
 		}
 
 		for _, apiVersion := range apiVersionElements {
@@ -521,10 +737,9 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 
 	if !state.ShowOnly.IsNull() && state.ShowOnly.Elements() != nil {
 		var showOnlyElements []types.String
-		diags := state.ShowOnly.ElementsAs(ctx, &showOnlyElements, false)
-		resp.Diagnostics.Append(diags...)
+		diags.Append(state.ShowOnly.ElementsAs(ctx, &showOnlyElements, false)...)
 		if diags.HasError() {
-			return
+			return diags
 		}
 
 		for _, raw := range showOnlyElements {
@@ -537,52 +752,67 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 
 	actionConfig, err := meta.GetHelmConfiguration(ctx, state.Namespace.ValueString())
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Failed to get Helm configuration",
 			fmt.Sprintf("There was an error retrieving Helm configuration for namespace %q: %s", state.Namespace.ValueString(), err),
 		)
-		return
+		return diags
 	}
-	diags := OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString())
+	diags.Append(OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, state.Repository.ValueString(), state.Chart.ValueString(), state.RepositoryUsername.ValueString(), state.RepositoryPassword.ValueString())...)
 	if diags.HasError() {
-		resp.Diagnostics.Append(diags...)
-		return
+		return diags
 	}
 	client := action.NewInstall(actionConfig)
 
-	cpo, chartName, cpoDiags := chartPathOptionsModel(&state, meta, &client.ChartPathOptions)
-	resp.Diagnostics.Append(cpoDiags...)
-	if resp.Diagnostics.HasError() {
-		return
+	cpo, chartName, cpoDiags := chartPathOptionsModel(state, meta, &client.ChartPathOptions)
+	diags.Append(cpoDiags...)
+	if diags.HasError() {
+		return diags
 	}
 
-	c, chartPath, chartDiags := getChartModel(ctx, &state, meta, chartName, cpo)
-	resp.Diagnostics.Append(chartDiags...)
-	if resp.Diagnostics.HasError() {
-		return
+	c, chartPath, chartDiags := getChartModel(ctx, state, meta, chartName, cpo)
+	diags.Append(chartDiags...)
+	if diags.HasError() {
+		return diags
 	}
 
-	updated, depDiags := checkChartDependenciesModel(ctx, &state, c, chartPath, meta)
-	resp.Diagnostics.Append(depDiags...)
-	if resp.Diagnostics.HasError() {
-		return
+	updated, depDiags := checkChartDependenciesModel(ctx, state, c, chartPath, meta)
+	diags.Append(depDiags...)
+	if diags.HasError() {
+		return diags
 	} else if updated {
 		c, err = loader.Load(chartPath)
 		if err != nil {
-			resp.Diagnostics.AddError("Error loading chart", fmt.Sprintf("Could not reload chart after updating dependencies: %s", err))
-			return
+			diags.AddError("Error loading chart", fmt.Sprintf("Could not reload chart after updating dependencies: %s", err))
+			return diags
 		}
 	}
 
-	values, valuesDiags := getValuesModel(ctx, &state)
-	resp.Diagnostics.Append(valuesDiags...)
-	if resp.Diagnostics.HasError() {
-		return
+	valuesFiles, valuesFilesDiags := readValuesFiles(ctx, meta, c, state.ValuesFiles)
+	diags.Append(valuesFilesDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	state.ValuesFilesSHA256, valuesFilesDiags = valuesFilesSHA256(ctx, state.ValuesFiles, valuesFiles)
+	diags.Append(valuesFilesDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	values, valuesDiags := getValuesModel(ctx, state, valuesFiles)
+	diags.Append(valuesDiags...)
+	if diags.HasError() {
+		return diags
+	}
+
+	diags.Append(validateValuesSchema(c, values, templateValuesSources(state, valuesFiles))...)
+	if diags.HasError() {
+		return diags
 	}
 
 	if err := isChartInstallable(c); err != nil {
-		resp.Diagnostics.AddError("Error checking if chart is installable", fmt.Sprintf("Chart is not installable: %s", err))
-		return
+		diags.AddError("Error checking if chart is installable", fmt.Sprintf("Chart is not installable: %s", err))
+		return diags
 	}
 	client.ChartPathOptions = *cpo
 	client.ClientOnly = false
@@ -604,14 +834,21 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	client.Description = state.Description.ValueString()
 	client.CreateNamespace = state.CreateNamespace.ValueBool()
 
+	pr, prDiags := newPostRenderer(ctx, state.PostRender)
+	diags.Append(prDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	client.PostRenderer = pr
+
 	if state.KubeVersion.ValueString() != "" {
 		parsedVer, err := chartutil.ParseKubeVersion(state.KubeVersion.ValueString())
 		if err != nil {
-			resp.Diagnostics.AddError(
+			diags.AddError(
 				"Failed to parse Kubernetes version",
 				fmt.Sprintf("couldn't parse string %q into kube-version: %s", state.KubeVersion.ValueString(), err),
 			)
-			return
+			return diags
 		}
 		client.KubeVersion = parsedVer
 	}
@@ -624,11 +861,11 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 
 	rel, err := client.Run(c, values)
 	if err != nil {
-		resp.Diagnostics.AddError(
+		diags.AddError(
 			"Error running Helm install",
 			fmt.Sprintf("Error running Helm install: %s", err),
 		)
-		return
+		return diags
 	}
 
 	var manifests bytes.Buffer
@@ -688,7 +925,7 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 			}
 
 			if missing {
-				resp.Diagnostics.AddError(
+				diags.AddError(
 					"Template Not Found",
 					fmt.Sprintf("Could not find template %q in chart", f),
 				)
@@ -722,10 +959,10 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	for i, crd := range chartCRDs {
 		listElements[i] = types.StringValue(crd)
 	}
-	listValue, diags := types.ListValue(types.StringType, listElements)
+	listValue, listDiags := types.ListValue(types.StringType, listElements)
+	diags.Append(listDiags...)
 	if diags.HasError() {
-		resp.Diagnostics.Append(diags...)
-		return
+		return diags
 	}
 	state.CRDs = listValue
 	// Convert computedManifests to types.Map
@@ -733,23 +970,44 @@ func (d *HelmTemplate) Read(ctx context.Context, req datasource.ReadRequest, res
 	for k, v := range computedManifests {
 		elements[k] = types.StringValue(v)
 	}
-	mapValue, diags := types.MapValue(types.StringType, elements)
+	mapValue, mapDiags := types.MapValue(types.StringType, elements)
+	diags.Append(mapDiags...)
 	if diags.HasError() {
-		resp.Diagnostics.Append(diags...)
-		return
+		return diags
 	}
 	state.Manifests = mapValue
 
 	state.Manifest = types.StringValue(computedManifest.String())
 	state.Notes = types.StringValue(rel.Info.Notes)
+
+	effective, effectiveDiags := effectiveValues(c, values, func(values map[string]interface{}) {
+		cloakSetValuesModel(values, state)
+	})
+	diags.Append(effectiveDiags...)
+	if diags.HasError() {
+		return diags
+	}
+	state.EffectiveValues = effective
 	state.ID = types.StringValue(state.Name.ValueString())
 
-	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+	return diags
 }
 
-func getValuesModel(ctx context.Context, model *HelmTemplateModel) (map[string]interface{}, diag.Diagnostics) {
+func getValuesModel(ctx context.Context, model *HelmTemplateModel, valuesFiles []valuesFile) (map[string]interface{}, diag.Diagnostics) {
 	base := map[string]interface{}{}
 	var diags diag.Diagnostics
+	lm := newListMerge(model.ListMerge)
+
+	// Process "values_files" attribute
+	for _, f := range valuesFiles {
+		currentMap := map[string]interface{}{}
+		if err := yaml.Unmarshal(f.content, &currentMap); err != nil {
+			diags.AddError("Error unmarshaling values file", fmt.Sprintf("Unable to parse values file %s: %s", f.name, err))
+			return nil, diags
+		}
+
+		base = lm.mergeMaps(base, currentMap)
+	}
 
 	// Process "values" attribute
 	for _, raw := range model.Values.Elements() {
@@ -774,7 +1032,7 @@ func getValuesModel(ctx context.Context, model *HelmTemplateModel) (map[string]i
 			return nil, diags
 		}
 
-		base = mergeMaps(base, currentMap)
+		base = lm.mergeMaps(base, currentMap)
 	}
 
 	// Process "set" attribute
@@ -805,7 +1063,7 @@ func getValuesModel(ctx context.Context, model *HelmTemplateModel) (map[string]i
 		}
 
 		for _, setList := range setListSlice {
-			setListDiags := applySetListValue(ctx, base, setList)
+			setListDiags := applySetListValue(ctx, base, setList, lm)
 			diags.Append(setListDiags...)
 			if diags.HasError() {
 				return nil, diags
@@ -998,7 +1256,7 @@ func applySetValue(base map[string]interface{}, set SetValue) diag.Diagnostics {
 	return diags
 }
 
-func applySetListValue(ctx context.Context, base map[string]interface{}, setList SetListValue) diag.Diagnostics {
+func applySetListValue(ctx context.Context, base map[string]interface{}, setList SetListValue, lm listMerge) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	name := setList.Name.ValueString()
@@ -1021,11 +1279,19 @@ func applySetListValue(ctx context.Context, base map[string]interface{}, setList
 
 	listString := strings.Join(listStringArray, ",")
 
-	// Parse the joined string into the base map
-	if err := strvals.ParseInto(fmt.Sprintf("%s={%s}", name, listString), base); err != nil {
+	// Parse the joined string into the base map, or merge it with the
+	// current lists unless they are replaced
+	target := base
+	if lm.strategy != listMergeReplace {
+		target = map[string]interface{}{}
+	}
+	if err := strvals.ParseInto(fmt.Sprintf("%s={%s}", name, listString), target); err != nil {
 		diags.AddError("Error parsing list value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, listString, err))
 		return diags
 	}
+	if lm.strategy != listMergeReplace {
+		lm.mergeInto(base, target)
+	}
 
 	return diags
 }
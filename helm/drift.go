@@ -0,0 +1,374 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	kresource "k8s.io/apimachinery/pkg/api/resource"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// driftValueMaxLength is the length after which the values reported in the
+// drift summary are truncated.
+const driftValueMaxLength = 100
+
+type DriftModel struct {
+	ForceUpdate  types.Bool `tfsdk:"force_update"`
+	IgnoreFields types.List `tfsdk:"ignore_fields"`
+}
+
+// driftedObject is an object of a release whose live state differs from the
+// last applied manifest.
+type driftedObject struct {
+	APIVersion string
+	Kind       string
+	Namespace  string
+	Name       string
+	Deleted    bool
+	Fields     []driftedField
+}
+
+// driftedField is a field of an object whose live value differs from the
+// value in the last applied manifest.
+type driftedField struct {
+	Path     string
+	Expected string
+	Actual   string
+}
+
+// noDrift returns the drift attribute of a release that was just applied or
+// whose drift is not detected.
+func noDrift(model *DriftModel) types.List {
+	if model == nil {
+		return types.ListNull(types.ObjectType{AttrTypes: driftAttrTypes()})
+	}
+	return types.ListValueMust(types.ObjectType{AttrTypes: driftAttrTypes()}, []attr.Value{})
+}
+
+// detectDrift compares the live objects of a release to its last applied
+// manifest, stores the drifted objects in the drift attribute and reports
+// them in a warning.
+func detectDrift(ctx context.Context, actionConfig *action.Configuration, r *release.Release, state *HelmReleaseModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	state.Drift = noDrift(state.DriftDetection)
+
+	var ignoreFields []string
+	if !state.DriftDetection.IgnoreFields.IsNull() {
+		diags.Append(state.DriftDetection.IgnoreFields.ElementsAs(ctx, &ignoreFields, false)...)
+		if diags.HasError() {
+			return diags
+		}
+	}
+	sensitiveValues := setSensitiveValues(ctx, state)
+
+	var drifted []driftedObject
+	_, resDiags := mapResources(ctx, actionConfig, r, func(i *resource.Info) (runtime.Object, error) {
+		desired, ok := i.Object.(*unstructured.Unstructured)
+		if !ok {
+			return i.Object, nil
+		}
+		object := driftedObject{
+			APIVersion: desired.GetAPIVersion(),
+			Kind:       desired.GetKind(),
+			Namespace:  i.Namespace,
+			Name:       i.Name,
+		}
+
+		live, err := resource.NewHelper(i.Client, i.Mapping).Get(i.Namespace, i.Name)
+		if err != nil {
+			if apierrors.IsNotFound(err) {
+				object.Deleted = true
+				drifted = append(drifted, object)
+			}
+			return nil, err
+		}
+		liveObject, err := runtime.DefaultUnstructuredConverter.ToUnstructured(live)
+		if err != nil {
+			return nil, err
+		}
+
+		object.Fields = diffLiveObject(desired.Object, liveObject, ignoreFields)
+		if len(object.Fields) > 0 {
+			for f := range object.Fields {
+				object.Fields[f].Expected = redactSetSensitiveValues(object.Fields[f].Expected, sensitiveValues)
+				object.Fields[f].Actual = redactSetSensitiveValues(object.Fields[f].Actual, sensitiveValues)
+			}
+			drifted = append(drifted, object)
+		}
+		return live, nil
+	})
+	if resDiags.HasError() {
+		diags.AddWarning(
+			"Unable to detect drift",
+			fmt.Sprintf("Could not compare the live objects of release %q to its manifest: %s", r.Name, resDiags.Errors()),
+		)
+		return diags
+	}
+	if len(drifted) == 0 {
+		tflog.Debug(ctx, fmt.Sprintf("No drift detected for release %q", r.Name))
+		return diags
+	}
+
+	elements := make([]attr.Value, 0, len(drifted))
+	for _, object := range drifted {
+		obj, objDiags := driftedObjectValue(object)
+		diags.Append(objDiags...)
+		if diags.HasError() {
+			return diags
+		}
+		elements = append(elements, obj)
+	}
+	state.Drift = types.ListValueMust(types.ObjectType{AttrTypes: driftAttrTypes()}, elements)
+
+	summary := driftSummary(drifted)
+	if state.DriftDetection.ForceUpdate.ValueBool() {
+		summary += "\nThe release will be upgraded to restore its manifest."
+	} else {
+		summary += "\nSet drift_detection.force_update to restore the manifest of the release on the next apply."
+	}
+	diags.AddWarning(fmt.Sprintf("Drift detected in release %q", r.Name), summary)
+	return diags
+}
+
+// diffLiveObject returns the fields of the desired object whose value differs
+// in the live object. Fields that are only set in the live object, such as
+// defaults and fields managed by controllers, are not drift.
+func diffLiveObject(desired, live map[string]any, ignoreFields []string) []driftedField {
+	desired = runtime.DeepCopyJSON(desired)
+	delete(desired, "status")
+	if kind, _ := desired["kind"].(string); kind == "Secret" {
+		secretStringDataToData(desired)
+	}
+
+	var fields []driftedField
+	diffLiveValue("", desired, live, true, &fields)
+
+	kept := fields[:0]
+	for _, f := range fields {
+		if !isIgnoredField(f.Path, ignoreFields) {
+			kept = append(kept, f)
+		}
+	}
+	if kind, _ := desired["kind"].(string); kind == "Secret" {
+		for i := range kept {
+			if strings.HasPrefix(kept[i].Path, "data") {
+				kept[i].Expected = sensitiveContentValue
+				kept[i].Actual = sensitiveContentValue
+			}
+		}
+	}
+	return kept
+}
+
+func diffLiveValue(path string, desired, live any, found bool, fields *[]driftedField) {
+	if !found {
+		if isEmptyValue(desired) {
+			return
+		}
+		*fields = append(*fields, driftedField{Path: path, Expected: driftValue(desired), Actual: "(missing)"})
+		return
+	}
+
+	switch d := desired.(type) {
+	case map[string]any:
+		l, ok := live.(map[string]any)
+		if !ok {
+			if len(d) == 0 && live == nil {
+				return
+			}
+			*fields = append(*fields, driftedField{Path: path, Expected: driftValue(desired), Actual: driftValue(live)})
+			return
+		}
+		keys := make([]string, 0, len(d))
+		for k := range d {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			v, ok := l[k]
+			diffLiveValue(driftFieldPath(path, k), d[k], v, ok, fields)
+		}
+	case []any:
+		l, ok := live.([]any)
+		if !ok || len(l) != len(d) {
+			if len(d) == 0 && live == nil {
+				return
+			}
+			*fields = append(*fields, driftedField{Path: path, Expected: driftValue(desired), Actual: driftValue(live)})
+			return
+		}
+		for i := range d {
+			diffLiveValue(fmt.Sprintf("%s[%d]", path, i), d[i], l[i], true, fields)
+		}
+	default:
+		if !scalarValuesEqual(desired, live) {
+			*fields = append(*fields, driftedField{Path: path, Expected: driftValue(desired), Actual: driftValue(live)})
+		}
+	}
+}
+
+// scalarValuesEqual compares scalar values the way the API server normalizes
+// them, e.g. 80 and "80" or 0.5 and "500m" are equal.
+func scalarValuesEqual(desired, live any) bool {
+	if desired == nil || live == nil {
+		return desired == nil && live == nil
+	}
+	d, l := fmt.Sprint(desired), fmt.Sprint(live)
+	if d == l {
+		return true
+	}
+	dq, err := kresource.ParseQuantity(d)
+	if err != nil {
+		return false
+	}
+	lq, err := kresource.ParseQuantity(l)
+	if err != nil {
+		return false
+	}
+	return dq.Cmp(lq) == 0
+}
+
+func isEmptyValue(v any) bool {
+	switch v := v.(type) {
+	case nil:
+		return true
+	case map[string]any:
+		return len(v) == 0
+	case []any:
+		return len(v) == 0
+	}
+	return false
+}
+
+// driftFieldPath appends a key to a field path. Keys containing dots, such as
+// most label names, are quoted.
+func driftFieldPath(path, key string) string {
+	if strings.ContainsAny(key, ".[]") {
+		return fmt.Sprintf("%s[%q]", path, key)
+	}
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+// isIgnoredField returns whether a field path is, or is nested in, one of the
+// ignored fields.
+func isIgnoredField(path string, ignoreFields []string) bool {
+	for _, ignored := range ignoreFields {
+		if path == ignored || strings.HasPrefix(path, ignored+".") || strings.HasPrefix(path, ignored+"[") {
+			return true
+		}
+	}
+	return false
+}
+
+// secretStringDataToData moves the stringData of a Secret to its data, as the
+// API server does.
+func secretStringDataToData(secret map[string]any) {
+	stringData, _ := secret["stringData"].(map[string]any)
+	if stringData == nil {
+		return
+	}
+	data, _ := secret["data"].(map[string]any)
+	if data == nil {
+		data = map[string]any{}
+	}
+	for k, v := range stringData {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(fmt.Sprint(v)))
+	}
+	secret["data"] = data
+	delete(secret, "stringData")
+}
+
+func driftValue(v any) string {
+	if v == nil {
+		return "null"
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	s := string(b)
+	if len(s) > driftValueMaxLength {
+		s = s[:driftValueMaxLength] + "..."
+	}
+	return s
+}
+
+// driftSummary renders drifted objects for the drift warning.
+func driftSummary(drifted []driftedObject) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d object(s) differ from the last applied manifest:\n", len(drifted))
+	for _, object := range drifted {
+		name := object.Name
+		if object.Namespace != "" {
+			name = object.Namespace + "/" + object.Name
+		}
+		fmt.Fprintf(&b, "\n%s %s (%s)", object.Kind, name, object.APIVersion)
+		if object.Deleted {
+			b.WriteString(": deleted\n")
+			continue
+		}
+		b.WriteString(":\n")
+		for _, f := range object.Fields {
+			fmt.Fprintf(&b, "  %s: expected %s, found %s\n", f.Path, f.Expected, f.Actual)
+		}
+	}
+	return b.String()
+}
+
+func driftedObjectValue(object driftedObject) (types.Object, diag.Diagnostics) {
+	fieldType := types.ObjectType{AttrTypes: driftFieldAttrTypes()}
+	fields := make([]attr.Value, 0, len(object.Fields))
+	for _, f := range object.Fields {
+		fields = append(fields, types.ObjectValueMust(fieldType.AttrTypes, map[string]attr.Value{
+			"actual":   types.StringValue(f.Actual),
+			"expected": types.StringValue(f.Expected),
+			"path":     types.StringValue(f.Path),
+		}))
+	}
+	return types.ObjectValue(driftAttrTypes(), map[string]attr.Value{
+		"api_version": types.StringValue(object.APIVersion),
+		"deleted":     types.BoolValue(object.Deleted),
+		"fields":      types.ListValueMust(fieldType, fields),
+		"kind":        types.StringValue(object.Kind),
+		"name":        types.StringValue(object.Name),
+		"namespace":   types.StringValue(object.Namespace),
+	})
+}
+
+func driftAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"api_version": types.StringType,
+		"deleted":     types.BoolType,
+		"fields":      types.ListType{ElemType: types.ObjectType{AttrTypes: driftFieldAttrTypes()}},
+		"kind":        types.StringType,
+		"name":        types.StringType,
+		"namespace":   types.StringType,
+	}
+}
+
+func driftFieldAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"actual":   types.StringType,
+		"expected": types.StringType,
+		"path":     types.StringType,
+	}
+}
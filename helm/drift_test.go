@@ -0,0 +1,117 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffLiveObject(t *testing.T) {
+	desired := map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name": "test",
+			"labels": map[string]any{
+				"app.kubernetes.io/name": "test",
+			},
+		},
+		"spec": map[string]any{
+			"replicas": int64(3),
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{
+							"name":  "nginx",
+							"image": "nginx:1.19.5",
+							"ports": []any{
+								map[string]any{"containerPort": "80"},
+							},
+							"resources": map[string]any{
+								"limits": map[string]any{"cpu": 0.5},
+							},
+							"env": []any{},
+						},
+					},
+				},
+			},
+		},
+	}
+	live := map[string]any{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata": map[string]any{
+			"name":            "test",
+			"resourceVersion": "42",
+			"labels": map[string]any{
+				"app.kubernetes.io/name": "edited",
+			},
+		},
+		"spec": map[string]any{
+			"replicas":             int64(5),
+			"revisionHistoryLimit": int64(10),
+			"template": map[string]any{
+				"spec": map[string]any{
+					"containers": []any{
+						map[string]any{
+							"name":  "nginx",
+							"image": "nginx:1.21.0",
+							"ports": []any{
+								map[string]any{"containerPort": int64(80), "protocol": "TCP"},
+							},
+							"resources": map[string]any{
+								"limits": map[string]any{"cpu": "500m"},
+							},
+						},
+					},
+				},
+			},
+		},
+		"status": map[string]any{"replicas": int64(5)},
+	}
+
+	expected := []driftedField{
+		{Path: `metadata.labels["app.kubernetes.io/name"]`, Expected: `"test"`, Actual: `"edited"`},
+		{Path: "spec.replicas", Expected: "3", Actual: "5"},
+		{Path: "spec.template.spec.containers[0].image", Expected: `"nginx:1.19.5"`, Actual: `"nginx:1.21.0"`},
+	}
+	if fields := diffLiveObject(desired, live, nil); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fields)
+	}
+
+	expected = []driftedField{
+		{Path: "spec.template.spec.containers[0].image", Expected: `"nginx:1.19.5"`, Actual: `"nginx:1.21.0"`},
+	}
+	ignoreFields := []string{"metadata.labels", "spec.replicas"}
+	if fields := diffLiveObject(desired, live, ignoreFields); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fields)
+	}
+}
+
+func TestDiffLiveObject_secret(t *testing.T) {
+	desired := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "test"},
+		"stringData": map[string]any{"password": "s3cr3t", "user": "admin"},
+	}
+	live := map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata":   map[string]any{"name": "test"},
+		"data": map[string]any{
+			"password": "ZWRpdGVk",
+			"user":     "YWRtaW4=",
+		},
+		"type": "Opaque",
+	}
+
+	expected := []driftedField{
+		{Path: "data.password", Expected: sensitiveContentValue, Actual: sensitiveContentValue},
+	}
+	if fields := diffLiveObject(desired, live, nil); !reflect.DeepEqual(fields, expected) {
+		t.Errorf("expected %+v, got %+v", expected, fields)
+	}
+}
@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+
+	"github.com/hashicorp/terraform-plugin-framework/datasource"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/provider"
+	providerschema "github.com/hashicorp/terraform-plugin-framework/provider/schema"
+	"github.com/hashicorp/terraform-plugin-framework/providerserver"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	resourceschema "github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
+)
+
+// ephemeralProviderFactories adds the echo provider to the helm provider.
+// The echo provider copies the data of its configuration to the state of its
+// echo resource, so that the results of ephemeral resources can be checked.
+// It stands in for the echo provider of terraform-plugin-testing, which
+// doesn't support the version of terraform-plugin-go of this provider.
+func ephemeralProviderFactories() map[string]func() (tfprotov6.ProviderServer, error) {
+	factories := map[string]func() (tfprotov6.ProviderServer, error){
+		"echo": providerserver.NewProtocol6WithError(&echoProvider{}),
+	}
+	for name, factory := range protoV6ProviderFactories() {
+		factories[name] = factory
+	}
+	return factories
+}
+
+type echoProvider struct{}
+
+func (p *echoProvider) Metadata(ctx context.Context, req provider.MetadataRequest, resp *provider.MetadataResponse) {
+	resp.TypeName = "echo"
+}
+
+func (p *echoProvider) Schema(ctx context.Context, req provider.SchemaRequest, resp *provider.SchemaResponse) {
+	resp.Schema = providerschema.Schema{
+		Attributes: map[string]providerschema.Attribute{
+			"data": providerschema.DynamicAttribute{
+				Required: true,
+			},
+		},
+	}
+}
+
+func (p *echoProvider) Configure(ctx context.Context, req provider.ConfigureRequest, resp *provider.ConfigureResponse) {
+	var data types.Dynamic
+	resp.Diagnostics.Append(req.Config.GetAttribute(ctx, path.Root("data"), &data)...)
+	resp.ResourceData = data
+}
+
+func (p *echoProvider) Resources(ctx context.Context) []func() resource.Resource {
+	return []func() resource.Resource{
+		func() resource.Resource { return &echoResource{} },
+	}
+}
+
+func (p *echoProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
+	return nil
+}
+
+type echoResource struct {
+	data types.Dynamic
+}
+
+func (r *echoResource) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName
+}
+
+func (r *echoResource) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = resourceschema.Schema{
+		Attributes: map[string]resourceschema.Attribute{
+			"data": resourceschema.DynamicAttribute{
+				Computed: true,
+			},
+		},
+	}
+}
+
+func (r *echoResource) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if data, ok := req.ProviderData.(types.Dynamic); ok {
+		r.data = data
+	}
+}
+
+func (r *echoResource) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("data"), r.data)...)
+}
+
+func (r *echoResource) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+}
+
+func (r *echoResource) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("data"), r.data)...)
+}
+
+func (r *echoResource) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+}
@@ -0,0 +1,54 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+)
+
+// effectiveValues returns as JSON the values a chart is rendered with: its
+// default values and the ones of its subcharts, coalesced with config. cloak is
+// called on a copy of the values to hide the sensitive ones.
+func effectiveValues(c *chart.Chart, config map[string]interface{}, cloak func(map[string]interface{})) (types.String, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if config == nil {
+		config = map[string]interface{}{}
+	}
+	coalesced, err := chartutil.CoalesceValues(c, config)
+	if err != nil {
+		diags.AddError("Error coalescing values", fmt.Sprintf("Unable to coalesce the values of chart %s: %s", c.Name(), err))
+		return types.StringNull(), diags
+	}
+
+	// The coalesced values share maps with the chart, they are copied through
+	// JSON before being cloaked
+	asJSON, err := json.Marshal(coalesced)
+	if err != nil {
+		diags.AddError("Error marshaling values", fmt.Sprintf("unable to marshal values: %s", err))
+		return types.StringNull(), diags
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(asJSON, &values); err != nil {
+		diags.AddError("Error unmarshaling values", fmt.Sprintf("unable to unmarshal values: %s", err))
+		return types.StringNull(), diags
+	}
+	if values == nil {
+		values = map[string]interface{}{}
+	}
+	cloak(values)
+
+	asJSON, err = json.Marshal(values)
+	if err != nil {
+		diags.AddError("Error marshaling values", fmt.Sprintf("unable to marshal values: %s", err))
+		return types.StringNull(), diags
+	}
+	return types.StringValue(string(asJSON)), diags
+}
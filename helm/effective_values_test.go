@@ -0,0 +1,83 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestEffectiveValues(t *testing.T) {
+	ctx := context.Background()
+
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "parent", Version: "1.0.0", APIVersion: chart.APIVersionV2},
+		Values: map[string]interface{}{
+			"service":  map[string]interface{}{"port": 80, "type": "ClusterIP"},
+			"password": "",
+		},
+	}
+	c.AddDependency(&chart.Chart{
+		Metadata: &chart.Metadata{Name: "child", Version: "1.0.0", APIVersion: chart.APIVersionV2},
+		Values: map[string]interface{}{
+			"enabled": true,
+			"image":   map[string]interface{}{"tag": "1.0"},
+		},
+	})
+
+	setType := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name":  types.StringType,
+			"type":  types.StringType,
+			"value": types.StringType,
+		},
+	}
+	setSensitive, diags := types.ListValueFrom(ctx, setType, []setResourceModel{
+		{Name: types.StringValue("password"), Value: types.StringValue("hunter2"), Type: types.StringValue("auto")},
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	state := &HelmReleaseModel{SetSensitive: setSensitive}
+
+	config := map[string]interface{}{
+		"password": "hunter2",
+		"service":  map[string]interface{}{"type": "NodePort"},
+		"child":    map[string]interface{}{"image": map[string]interface{}{"tag": "2.0"}},
+	}
+	effective, diags := effectiveValues(c, config, func(values map[string]interface{}) {
+		cloakSetValues(values, state)
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	var values map[string]interface{}
+	if err := json.Unmarshal([]byte(effective.ValueString()), &values); err != nil {
+		t.Fatal(err)
+	}
+	expected := map[string]interface{}{
+		"password": "(sensitive value)",
+		"service":  map[string]interface{}{"port": float64(80), "type": "NodePort"},
+		"child": map[string]interface{}{
+			"enabled": true,
+			"global":  map[string]interface{}{},
+			"image":   map[string]interface{}{"tag": "2.0"},
+		},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values:\n%#v\nexpected:\n%#v", values, expected)
+	}
+
+	// The chart and the config are left untouched
+	if c.Values["password"] != "" || config["password"] != "hunter2" {
+		t.Errorf("expected the chart and the config not to be modified, got %v and %v", c.Values, config)
+	}
+}
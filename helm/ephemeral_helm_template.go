@@ -0,0 +1,544 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework-timeouts/ephemeral/timeouts"
+	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral/schema"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+var (
+	_ ephemeral.EphemeralResource              = &HelmTemplateEphemeral{}
+	_ ephemeral.EphemeralResourceWithConfigure = &HelmTemplateEphemeral{}
+)
+
+func NewHelmTemplateEphemeral() ephemeral.EphemeralResource {
+	return &HelmTemplateEphemeral{}
+}
+
+// HelmTemplateEphemeral represents the ephemeral resource rendering Helm chart
+// templates. Unlike the helm_template data source, the rendered manifests are
+// never written to the plan or the state.
+type HelmTemplateEphemeral struct {
+	meta *Meta
+}
+
+// HelmTemplateEphemeralModel holds the attributes of the helm_template ephemeral resource
+type HelmTemplateEphemeralModel struct {
+	HelmTemplateModel
+	Timeouts timeouts.Value `tfsdk:"timeouts"`
+}
+
+func (e *HelmTemplateEphemeral) Configure(ctx context.Context, req ephemeral.ConfigureRequest, resp *ephemeral.ConfigureResponse) {
+	if req.ProviderData != nil {
+		e.meta = req.ProviderData.(*Meta)
+	}
+}
+
+func (e *HelmTemplateEphemeral) Metadata(ctx context.Context, req ephemeral.MetadataRequest, resp *ephemeral.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_template"
+}
+
+func (e *HelmTemplateEphemeral) Schema(ctx context.Context, req ephemeral.SchemaRequest, resp *ephemeral.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Ephemeral resource to render Helm chart templates without persisting the rendered manifests.",
+		Attributes: map[string]schema.Attribute{
+			"api_versions": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Kubernetes api versions used for Capabilities.APIVersions.",
+			},
+			"atomic": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, the installation process purges the chart on fail. The 'wait' flag will be set automatically if 'atomic' is used.",
+			},
+			"chart": schema.StringAttribute{
+				Required:    true,
+				Description: "Chart name to be installed. A path may be used.",
+			},
+			"crds": schema.ListAttribute{
+				Optional:    true,
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "List of rendered CRDs from the chart.",
+			},
+			"create_namespace": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Create the namespace if it does not exist.",
+			},
+			"dependency_update": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Run helm dependency update before installing the chart.",
+			},
+			"description": schema.StringAttribute{
+				Optional:    true,
+				Description: "Add a custom description.",
+			},
+			"devel": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Use chart development versions, too. Equivalent to version '>0.0.0-0'. If `version` is set, this is ignored.",
+			},
+			"disable_openapi_validation": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, the installation process will not validate rendered templates against the Kubernetes OpenAPI Schema.",
+			},
+			"disable_webhooks": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Prevent hooks from running.",
+			},
+			"effective_values": schema.StringAttribute{
+				Computed:    true,
+				Sensitive:   true,
+				Description: "The values the chart was rendered with as JSON: the default values of the chart and its subcharts coalesced with the values. Sensitive values are cloaked.",
+			},
+			"id": schema.StringAttribute{
+				Computed: true,
+			},
+			"include_crds": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Include CRDs in the templated output.",
+			},
+			"is_upgrade": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Set .Release.IsUpgrade instead of .Release.IsInstall.",
+			},
+			"keyring": schema.StringAttribute{
+				Optional:    true,
+				Description: "Location of public keys used for verification. Used only if `verify` is true.",
+			},
+			"kube_version": schema.StringAttribute{
+				Optional:    true,
+				Description: "Kubernetes version used for Capabilities.KubeVersion.",
+			},
+			"list_merge": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "How the lists of the values files, values documents and set_list are merged. By default, lists are replaced.",
+				Attributes: map[string]schema.Attribute{
+					"key": schema.StringAttribute{
+						Optional:    true,
+						Description: "Key identifying the items of the lists with the merge_by_key strategy. Defaults to `name`.",
+					},
+					"strategy": schema.StringAttribute{
+						Required:    true,
+						Description: "One of `replace`, `append` or `merge_by_key`.",
+						Validators: []validator.String{
+							stringvalidator.OneOf(listMergeStrategies...),
+						},
+					},
+				},
+			},
+			"manifest": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Concatenated rendered chart templates. This corresponds to the output of the `helm template` command.",
+			},
+			"manifests": schema.MapAttribute{
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				ElementType: types.StringType,
+				Description: "Map of rendered chart templates indexed by the template name.",
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Release name",
+			},
+			"namespace": schema.StringAttribute{
+				Optional:    true,
+				Description: "Namespace to install the release into.",
+			},
+			"notes": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Sensitive:   true,
+				Description: "Rendered notes if the chart contains a `NOTES.txt`.",
+			},
+			"pass_credentials": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Pass credentials to all domains",
+			},
+			"postrender": schema.SingleNestedAttribute{
+				Description: "Postrender command config",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"args": schema.ListAttribute{
+						Optional:    true,
+						Description: "An argument to the post-renderer (can specify multiple)",
+						ElementType: types.StringType,
+					},
+					"binary_path": schema.StringAttribute{
+						Optional:    true,
+						Description: "The common binary path",
+						Validators: []validator.String{
+							stringvalidator.ExactlyOneOf(
+								path.MatchRelative().AtParent().AtName("kustomize"),
+								path.MatchRelative().AtParent().AtName("steps"),
+							),
+						},
+					},
+					"env": schema.MapAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+						Description: "Environment variables added to the environment of the post-renderer command",
+					},
+					"env_sensitive": schema.MapAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						ElementType: types.StringType,
+						Description: "Sensitive environment variables added to the environment of the post-renderer command. They are redacted from logs and error messages",
+					},
+					"kustomize": kustomizePostRenderEphemeralSchema(),
+					"steps": schema.ListNestedAttribute{
+						Optional:    true,
+						Description: "Post-renderers run in order, each one receiving the output of the previous one",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"args": schema.ListAttribute{
+									Optional:    true,
+									Description: "An argument to the post-renderer (can specify multiple)",
+									ElementType: types.StringType,
+								},
+								"binary_path": schema.StringAttribute{
+									Optional:    true,
+									Description: "The command binary path",
+									Validators: []validator.String{
+										stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("kustomize")),
+									},
+								},
+								"env": schema.MapAttribute{
+									Optional:    true,
+									ElementType: types.StringType,
+									Description: "Environment variables added to the environment of the post-renderer command",
+								},
+								"env_sensitive": schema.MapAttribute{
+									Optional:    true,
+									Sensitive:   true,
+									ElementType: types.StringType,
+									Description: "Sensitive environment variables added to the environment of the post-renderer command. They are redacted from logs and error messages",
+								},
+								"kustomize": kustomizePostRenderEphemeralSchema(),
+								"timeout": schema.Int64Attribute{
+									Optional:    true,
+									Description: "Time in seconds after which the post-renderer command is killed",
+									Validators: []validator.Int64{
+										int64validator.AtLeast(1),
+									},
+								},
+								"working_dir": schema.StringAttribute{
+									Optional:    true,
+									Description: "Working directory of the post-renderer command. Relative binary paths are resolved from it",
+								},
+							},
+						},
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+					"timeout": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Time in seconds after which the post-renderer command is killed",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"working_dir": schema.StringAttribute{
+						Optional:    true,
+						Description: "Working directory of the post-renderer command. Relative binary paths are resolved from it",
+					},
+				},
+			},
+			"render_subchart_notes": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, render subchart notes along with the parent.",
+			},
+			"replace": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Re-use the given name, even if that name is already used. This is unsafe in production.",
+			},
+			"repository": schema.StringAttribute{
+				Optional:    true,
+				Description: "Repository where to locate the requested chart. If it is a URL the chart is installed without installing the repository.",
+			},
+			"repository_ca_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "The repository's CA file",
+			},
+			"repository_cert_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "The repository's cert file",
+			},
+			"repository_key_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "The repository's cert key file",
+			},
+			"repository_password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password for HTTP basic authentication",
+			},
+			"repository_username": schema.StringAttribute{
+				Optional:    true,
+				Description: "Username for HTTP basic authentication",
+			},
+			"reset_values": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When upgrading, reset the values to the ones built into the chart.",
+			},
+			"reuse_values": schema.BoolAttribute{
+				Optional:    true,
+				Description: "When upgrading, reuse the last release's values and merge in any overrides. If 'reset_values' is specified, this is ignored.",
+			},
+			"set": schema.SetNestedAttribute{
+				Description: "Custom values to be merged with the values",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required: true,
+						},
+						"value": schema.StringAttribute{
+							Optional: true,
+						},
+						"type": schema.StringAttribute{
+							Optional: true,
+							Computed: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("auto", "string", "literal"),
+							},
+						},
+					},
+				},
+			},
+			"set_list": schema.ListNestedAttribute{
+				Description: "Custom sensitive values to be merged with the values",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Optional: true,
+						},
+						"value": schema.ListAttribute{
+							Required:    true,
+							ElementType: types.StringType,
+						},
+					},
+				},
+			},
+			"set_sensitive": schema.SetNestedAttribute{
+				Description: "Custom sensitive values to be merged with the values",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required: true,
+						},
+						"value": schema.StringAttribute{
+							Required:  true,
+							Sensitive: true,
+						},
+						"type": schema.StringAttribute{
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("auto", "string", "literal"),
+							},
+						},
+					},
+				},
+			},
+			"set_wo": schema.ListNestedAttribute{
+				Description: "Write-only custom values to be merged with the values.",
+				Optional:    true,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"name": schema.StringAttribute{
+							Required: true,
+						},
+						"value": schema.StringAttribute{
+							Required: true,
+						},
+						"type": schema.StringAttribute{
+							Optional: true,
+							Validators: []validator.String{
+								stringvalidator.OneOf("auto", "string"),
+							},
+						},
+					},
+				},
+			},
+			"show_only": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "Only show manifests rendered from the given templates.",
+			},
+			"skip_crds": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, no CRDs will be installed. By default, CRDs are installed if not already present.",
+			},
+			"skip_tests": schema.BoolAttribute{
+				Optional:    true,
+				Description: "If set, tests will not be rendered. By default, tests are rendered.",
+			},
+			"timeout": schema.Int64Attribute{
+				Optional:    true,
+				Description: "Time in seconds to wait for any individual Kubernetes operation.",
+			},
+			"timeouts": timeouts.Attributes(ctx),
+			"validate": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Validate your manifests against the Kubernetes cluster you are currently pointing at. This is the same validation performed on an install.",
+			},
+			"values": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "List of values in raw yaml format to pass to helm.",
+			},
+			"values_files": schema.ListAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: "List of values files to pass to helm, merged before `values`. Accepts local paths, URLs supported by the helm getters and files of the chart.",
+			},
+			"values_files_sha256": schema.MapAttribute{
+				Computed:    true,
+				ElementType: types.StringType,
+				Description: "SHA256 checksums of the content of the values files, by name.",
+			},
+			"verify": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Verify the package before installing it.",
+			},
+			"version": schema.StringAttribute{
+				Optional:    true,
+				Computed:    true,
+				Description: "Specify the exact chart version to install. If this is not specified, the latest version is installed.",
+			},
+			"wait": schema.BoolAttribute{
+				Optional:    true,
+				Description: "Will wait until all resources are in a ready state before marking the release as successful.",
+			},
+		},
+	}
+}
+
+// Open renders the chart the same way the helm_template data source does. The
+// result is only handed to Terraform for the duration of the run.
+func (e *HelmTemplateEphemeral) Open(ctx context.Context, req ephemeral.OpenRequest, resp *ephemeral.OpenResponse) {
+	var model HelmTemplateEphemeralModel
+	resp.Diagnostics.Append(req.Config.Get(ctx, &model)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	openTimeout, diags := model.Timeouts.Open(ctx, 20*time.Minute)
+	resp.Diagnostics.Append(diags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, openTimeout)
+	defer cancel()
+
+	resp.Diagnostics.Append(renderTemplate(ctx, e.meta, &model.HelmTemplateModel)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(resp.Result.Set(ctx, &model)...)
+}
+
+func kustomizePostRenderEphemeralSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:    true,
+		Description: kustomizeDescription,
+		Attributes: map[string]schema.Attribute{
+			"common_annotations": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: kustomizeCommonAnnotationsDescription,
+			},
+			"common_labels": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: kustomizeCommonLabelsDescription,
+			},
+			"images": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: kustomizeImagesDescription,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"digest": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageDigestDescription,
+						},
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: kustomizeImageNameDescription,
+						},
+						"new_name": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageNewNameDescription,
+						},
+						"new_tag": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageNewTagDescription,
+						},
+					},
+				},
+			},
+			"kustomization": schema.StringAttribute{
+				Optional:    true,
+				Description: kustomizeKustomizationDescription,
+			},
+			"patches": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: kustomizePatchesDescription,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"patch": schema.StringAttribute{
+							Required:    true,
+							Description: kustomizePatchDescription,
+						},
+						"target": schema.SingleNestedAttribute{
+							Optional:    true,
+							Description: kustomizePatchTargetDescription,
+							Attributes: map[string]schema.Attribute{
+								"annotation_selector": schema.StringAttribute{
+									Optional: true,
+								},
+								"group": schema.StringAttribute{
+									Optional: true,
+								},
+								"kind": schema.StringAttribute{
+									Optional: true,
+								},
+								"label_selector": schema.StringAttribute{
+									Optional: true,
+								},
+								"name": schema.StringAttribute{
+									Optional: true,
+								},
+								"namespace": schema.StringAttribute{
+									Optional: true,
+								},
+								"version": schema.StringAttribute{
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,77 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
+	"github.com/hashicorp/terraform-plugin-testing/statecheck"
+	"github.com/hashicorp/terraform-plugin-testing/tfjsonpath"
+	"github.com/hashicorp/terraform-plugin-testing/tfversion"
+)
+
+func TestAccEphemeralTemplate_basic(t *testing.T) {
+	name := randName("ephemeral")
+	namespace := randName(testNamespacePrefix)
+
+	resource.Test(t, resource.TestCase{
+		TerraformVersionChecks: []tfversion.TerraformVersionCheck{
+			tfversion.SkipBelow(tfversion.Version1_10_0),
+		},
+		ProtoV6ProviderFactories: ephemeralProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccEphemeralHelmTemplateConfigBasic(testResourceName, namespace, name, "1.2.3", "templates/secrets.yaml"),
+				ConfigStateChecks: []statecheck.StateCheck{
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("manifest"),
+						knownvalue.StringRegexp(regexp.MustCompile(`(?s)kind: Secret.*cloaked: czNjcjN0`)),
+					),
+					statecheck.ExpectKnownValue(
+						"echo.test",
+						tfjsonpath.New("data").AtMapKey("manifests"),
+						knownvalue.MapExact(map[string]knownvalue.Check{
+							"templates/secrets.yaml": knownvalue.StringRegexp(regexp.MustCompile(`cloaked: czNjcjN0`)),
+						}),
+					),
+				},
+			},
+			{
+				Config:      testAccEphemeralHelmTemplateConfigBasic(testResourceName, namespace, name, "1.2.3", "templates/missing.yaml"),
+				ExpectError: regexp.MustCompile(`Could not find template "templates/missing.yaml" in chart`),
+			},
+		},
+	})
+}
+
+func testAccEphemeralHelmTemplateConfigBasic(resource, ns, name, version, template string) string {
+	return fmt.Sprintf(`
+		ephemeral "helm_template" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = %q
+			show_only  = [%q]
+
+			set_sensitive = [
+				{
+					name  = "cloakedData.cloaked"
+					value = "s3cr3t"
+				}
+			]
+		}
+
+		provider "echo" {
+			data = ephemeral.helm_template.%[1]s
+		}
+
+		resource "echo" "test" {}
+	`, resource, name, ns, testRepositoryURL, version, template)
+}
@@ -0,0 +1,361 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+)
+
+// The failure report of a release is bounded, so that it fits in a
+// diagnostic whatever the size of the release.
+const (
+	failureReportMaxObjects = 10
+	failureReportMaxPods    = 3
+	failureReportMaxEvents  = 5
+	failureReportLogLines   = 20
+	failureReportMaxLength  = 16384
+	failureReportTimeout    = 30 * time.Second
+)
+
+// failureSensitiveValues returns the values of the set_sensitive and set_wo
+// attributes of a release, which are redacted from its failure report.
+func failureSensitiveValues(ctx context.Context, plan, config *HelmReleaseModel) map[string]string {
+	sensitiveValues := map[string]string{}
+	for _, model := range []*HelmReleaseModel{plan, config} {
+		for _, v := range append(setSensitiveValues(ctx, model), setWOValues(ctx, model)...) {
+			sensitiveValues[v] = sensitiveContentValue
+		}
+	}
+	return sensitiveValues
+}
+
+// withFailureReport appends the failure report of a release to the detail of
+// an error diagnostic.
+func withFailureReport(detail, report string) string {
+	if report == "" {
+		return detail
+	}
+	return fmt.Sprintf("%s\n\n%s", detail, report)
+}
+
+// releaseFailureReport describes the objects of a release that exist but
+// aren't ready, along with their recent events, the phases of their pods, the
+// reasons their containers are waiting or terminated, and the last lines of
+// the logs of the crashing ones. The sensitive values are redacted from the
+// report. The report is empty when it can't be collected, or when all the
+// objects are ready.
+func releaseFailureReport(ctx context.Context, actionConfig *action.Configuration, plan *HelmReleaseModel, r *release.Release, sensitiveValues map[string]string) string {
+	if r == nil || r.Manifest == "" {
+		return ""
+	}
+	logID := fmt.Sprintf("[releaseFailureReport: %s]", r.Name)
+
+	// The report is also collected once the timeout of the operation expired,
+	// e.g. after a health check timed out
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), failureReportTimeout)
+	defer cancel()
+
+	resources, err := buildReleaseResources(actionConfig, r)
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("%s Unable to build the objects of the release: %s", logID, err))
+		return ""
+	}
+	kc, err := getKubeClient(actionConfig)
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("%s Unable to get the kubernetes client: %s", logID, err))
+		return ""
+	}
+	dynamicClient, err := kc.Factory.DynamicClient()
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("%s Unable to get the kubernetes client: %s", logID, err))
+		return ""
+	}
+	clientset, err := kc.Factory.KubernetesClientSet()
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("%s Unable to get the kubernetes client: %s", logID, err))
+		return ""
+	}
+	return failureReport(ctx, dynamicClient, clientset, resources, plan.WaitForJobs.ValueBool(), sensitiveValues)
+}
+
+// failureReport writes the failure report of the given objects of a release
+func failureReport(ctx context.Context, dynamicClient dynamic.Interface, clientset kubernetes.Interface, resources kube.ResourceList, waitForJobs bool, sensitiveValues map[string]string) string {
+	var b strings.Builder
+	reported, skipped := 0, 0
+	for _, info := range resources {
+		client := dynamicClient.Resource(info.Mapping.Resource)
+		var live *unstructured.Unstructured
+		var err error
+		if info.Mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+			live, err = client.Namespace(info.Namespace).Get(ctx, info.Name, metav1.GetOptions{})
+		} else {
+			live, err = client.Get(ctx, info.Name, metav1.GetOptions{})
+		}
+		if err != nil {
+			// Objects that don't exist, e.g. because an atomic release was
+			// uninstalled, have nothing to report
+			tflog.Debug(ctx, fmt.Sprintf("Unable to get %s %s: %s", info.Mapping.GroupVersionKind.Kind, info.Name, err))
+			continue
+		}
+		status, message := computeObjectStatus(live.Object, waitForJobs)
+		if status == statusCurrent {
+			continue
+		}
+		if reported == failureReportMaxObjects {
+			skipped++
+			continue
+		}
+		reported++
+		writeObjectReport(ctx, clientset, &b, live, status, message)
+	}
+	if reported == 0 {
+		return ""
+	}
+	if skipped > 0 {
+		fmt.Fprintf(&b, "... and %d more objects that are not ready\n", skipped)
+	}
+
+	// The report is redacted before it is truncated, so that no part of a
+	// sensitive value is left at its end
+	report := redactSensitiveValues(strings.TrimRight(b.String(), "\n"), sensitiveValues)
+	return "Objects of the release that are not ready:\n\n" + truncateReport(report, failureReportMaxLength)
+}
+
+// truncateReport truncates a report to at most maxLength bytes, at the end of
+// a line when possible and never in the middle of a rune
+func truncateReport(report string, maxLength int) string {
+	if len(report) <= maxLength {
+		return report
+	}
+	cut := strings.LastIndexByte(report[:maxLength], '\n')
+	if cut <= 0 {
+		cut = maxLength
+		for cut > 0 && !utf8.RuneStart(report[cut]) {
+			cut--
+		}
+	}
+	return report[:cut] + "\n... (truncated)"
+}
+
+// writeObjectReport writes the status and the recent events of an object
+// that isn't ready, followed by the report of its pods
+func writeObjectReport(ctx context.Context, clientset kubernetes.Interface, b *strings.Builder, object *unstructured.Unstructured, status objectStatus, message string) {
+	kind, namespace, name := object.GetKind(), object.GetNamespace(), object.GetName()
+	if namespace == "" {
+		fmt.Fprintf(b, "%s %s: %s", kind, name, status)
+	} else {
+		fmt.Fprintf(b, "%s %s/%s: %s", kind, namespace, name, status)
+	}
+	if message != "" {
+		fmt.Fprintf(b, ": %s", message)
+	}
+	b.WriteString("\n")
+	writeEvents(ctx, clientset, b, namespace, kind, name, "  ")
+
+	pods := objectPods(ctx, clientset, object)
+	for i, pod := range pods {
+		if i == failureReportMaxPods {
+			fmt.Fprintf(b, "  ... and %d more pods\n", len(pods)-i)
+			break
+		}
+		writePodReport(ctx, clientset, b, pod)
+	}
+	b.WriteString("\n")
+}
+
+// writeEvents writes the most recent events involving an object
+func writeEvents(ctx context.Context, clientset kubernetes.Interface, b *strings.Builder, namespace, kind, name, indent string) {
+	list, err := clientset.CoreV1().Events(namespace).List(ctx, metav1.ListOptions{
+		FieldSelector: fmt.Sprintf("involvedObject.kind=%s,involvedObject.name=%s", kind, name),
+	})
+	if err != nil {
+		tflog.Debug(ctx, fmt.Sprintf("Unable to list the events of %s %s: %s", kind, name, err))
+		return
+	}
+
+	var events []corev1.Event
+	for _, event := range list.Items {
+		if event.InvolvedObject.Kind == kind && event.InvolvedObject.Name == name {
+			events = append(events, event)
+		}
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	if len(events) > failureReportMaxEvents {
+		events = events[len(events)-failureReportMaxEvents:]
+	}
+	for _, event := range events {
+		fmt.Fprintf(b, "%s%s %s", indent, event.Type, event.Reason)
+		if event.Count > 1 {
+			fmt.Fprintf(b, " (x%d)", event.Count)
+		}
+		fmt.Fprintf(b, ": %s\n", strings.TrimSpace(event.Message))
+	}
+}
+
+// eventTime returns the time an event was last observed
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case event.Series != nil:
+		return event.Series.LastObservedTime.Time
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	}
+	return event.CreationTimestamp.Time
+}
+
+// objectPods returns the pods of an object, which is either a pod itself or
+// a workload selecting its pods, the ones that aren't ready first
+func objectPods(ctx context.Context, clientset kubernetes.Interface, object *unstructured.Unstructured) []corev1.Pod {
+	var pods []corev1.Pod
+	switch object.GetKind() {
+	case "Pod":
+		pod := corev1.Pod{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(object.Object, &pod); err != nil {
+			return nil
+		}
+		return []corev1.Pod{pod}
+	case "Deployment", "StatefulSet", "DaemonSet", "ReplicaSet", "Job":
+		s, found, _ := unstructured.NestedMap(object.Object, "spec", "selector")
+		if !found {
+			return nil
+		}
+		labelSelector := metav1.LabelSelector{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(s, &labelSelector); err != nil {
+			return nil
+		}
+		selector, err := metav1.LabelSelectorAsSelector(&labelSelector)
+		if err != nil || selector.Empty() {
+			return nil
+		}
+		list, err := clientset.CoreV1().Pods(object.GetNamespace()).List(ctx, metav1.ListOptions{LabelSelector: selector.String()})
+		if err != nil {
+			tflog.Debug(ctx, fmt.Sprintf("Unable to list the pods of %s %s: %s", object.GetKind(), object.GetName(), err))
+			return nil
+		}
+		pods = list.Items
+	}
+	sort.SliceStable(pods, func(i, j int) bool {
+		return !podReady(pods[i]) && podReady(pods[j])
+	})
+	return pods
+}
+
+func podReady(pod corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// writePodReport writes the phase and the events of a pod, the reasons its
+// containers are waiting or terminated, and the last lines of the logs of
+// the containers that crashed
+func writePodReport(ctx context.Context, clientset kubernetes.Interface, b *strings.Builder, pod corev1.Pod) {
+	fmt.Fprintf(b, "  Pod %s: %s", pod.Name, pod.Status.Phase)
+	if pod.Status.Reason != "" {
+		fmt.Fprintf(b, " (%s)", pod.Status.Reason)
+	}
+	if pod.Status.Message != "" {
+		fmt.Fprintf(b, ": %s", pod.Status.Message)
+	}
+	b.WriteString("\n")
+	writeEvents(ctx, clientset, b, pod.Namespace, "Pod", pod.Name, "    ")
+
+	statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, status := range statuses {
+		state := containerState(status)
+		if state == "" {
+			continue
+		}
+		fmt.Fprintf(b, "    Container %s: %s", status.Name, state)
+		if status.RestartCount > 0 {
+			fmt.Fprintf(b, ", restarted %d times", status.RestartCount)
+		}
+		b.WriteString("\n")
+
+		crashed, previous := containerCrashed(status)
+		if !crashed {
+			continue
+		}
+		lines := int64(failureReportLogLines)
+		logs, err := clientset.CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{
+			Container: status.Name,
+			Previous:  previous,
+			TailLines: &lines,
+		}).DoRaw(ctx)
+		if err != nil {
+			tflog.Debug(ctx, fmt.Sprintf("Unable to get the logs of container %s of pod %s: %s", status.Name, pod.Name, err))
+			continue
+		}
+		if trimmed := strings.TrimRight(string(logs), "\n"); trimmed != "" {
+			fmt.Fprintf(b, "      Last %d log lines:\n", failureReportLogLines)
+			for _, line := range strings.Split(trimmed, "\n") {
+				fmt.Fprintf(b, "        %s\n", line)
+			}
+		}
+	}
+}
+
+// containerState describes why a container is waiting or terminated, or
+// returns an empty string when it is running
+func containerState(status corev1.ContainerStatus) string {
+	switch {
+	case status.State.Waiting != nil:
+		state := fmt.Sprintf("waiting (%s)", status.State.Waiting.Reason)
+		if status.State.Waiting.Message != "" {
+			state = fmt.Sprintf("%s: %s", state, status.State.Waiting.Message)
+		}
+		return state
+	case status.State.Terminated != nil:
+		return terminatedState("terminated", status.State.Terminated)
+	case !status.Ready && status.LastTerminationState.Terminated != nil:
+		return terminatedState("not ready, last terminated", status.LastTerminationState.Terminated)
+	case !status.Ready:
+		return "running, not ready"
+	}
+	return ""
+}
+
+func terminatedState(prefix string, terminated *corev1.ContainerStateTerminated) string {
+	state := fmt.Sprintf("%s (%s, exit code %d)", prefix, terminated.Reason, terminated.ExitCode)
+	if terminated.Message != "" {
+		state = fmt.Sprintf("%s: %s", state, strings.TrimSpace(terminated.Message))
+	}
+	return state
+}
+
+// containerCrashed returns whether a container crashed, and if so, whether
+// the logs of the crash are the ones of its previous instance
+func containerCrashed(status corev1.ContainerStatus) (bool, bool) {
+	if terminated := status.State.Terminated; terminated != nil {
+		return terminated.ExitCode != 0, false
+	}
+	if terminated := status.LastTerminationState.Terminated; terminated != nil && status.RestartCount > 0 {
+		return terminated.ExitCode != 0 || terminated.Reason == "OOMKilled", true
+	}
+	return false, false
+}
@@ -0,0 +1,282 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"helm.sh/helm/v3/pkg/kube"
+	corev1 "k8s.io/api/core/v1"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+	dynamicfake "k8s.io/client-go/dynamic/fake"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestWriteObjectReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	event := func(name, kind, involved, reason, message string, age time.Duration) *corev1.Event {
+		return &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: name, Namespace: "apps"},
+			InvolvedObject: corev1.ObjectReference{Kind: kind, Name: involved, Namespace: "apps"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         reason,
+			Message:        message,
+			LastTimestamp:  metav1.NewTime(now.Add(-age)),
+		}
+	}
+	pod := func(name string, ready bool, statuses ...corev1.ContainerStatus) *corev1.Pod {
+		condition := corev1.ConditionFalse
+		if ready {
+			condition = corev1.ConditionTrue
+		}
+		return &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: "apps", Labels: map[string]string{"app": "web"}},
+			Status: corev1.PodStatus{
+				Phase:             corev1.PodRunning,
+				Conditions:        []corev1.PodCondition{{Type: corev1.PodReady, Status: condition}},
+				ContainerStatuses: statuses,
+			},
+		}
+	}
+
+	clientset := fake.NewClientset(
+		pod("web-ready", true, corev1.ContainerStatus{Name: "web", Ready: true}),
+		pod("web-crashing", false, corev1.ContainerStatus{
+			Name:         "web",
+			RestartCount: 4,
+			State: corev1.ContainerState{
+				Waiting: &corev1.ContainerStateWaiting{Reason: "CrashLoopBackOff", Message: "back-off 1m20s restarting failed container"},
+			},
+			LastTerminationState: corev1.ContainerState{
+				Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1},
+			},
+		}),
+		&corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: "apps", Labels: map[string]string{"app": "other"}}},
+		event("web.1", "Deployment", "web", "ScalingReplicaSet", "Scaled up replica set web-1 to 2", 5*time.Minute),
+		event("web.2", "Deployment", "web", "OldEvent", "first", 10*time.Minute),
+		event("web-crashing.1", "Pod", "web-crashing", "BackOff", "Back-off restarting failed container", time.Minute),
+		event("other.1", "Pod", "other", "Unrelated", "not reported", time.Minute),
+	)
+
+	deployment := &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "apps/v1",
+		"kind":       "Deployment",
+		"metadata":   map[string]interface{}{"name": "web", "namespace": "apps"},
+		"spec": map[string]interface{}{
+			"selector": map[string]interface{}{"matchLabels": map[string]interface{}{"app": "web"}},
+		},
+	}}
+
+	var b strings.Builder
+	writeObjectReport(ctx, clientset, &b, deployment, statusInProgress, "ready replicas: 1/2")
+	report := b.String()
+
+	expected := `Deployment apps/web: InProgress: ready replicas: 1/2
+  Warning OldEvent: first
+  Warning ScalingReplicaSet: Scaled up replica set web-1 to 2
+  Pod web-crashing: Running
+    Warning BackOff: Back-off restarting failed container
+    Container web: waiting (CrashLoopBackOff): back-off 1m20s restarting failed container, restarted 4 times
+      Last 20 log lines:
+        fake logs
+  Pod web-ready: Running
+
+`
+	if report != expected {
+		t.Errorf("unexpected report:\n%s\nexpected:\n%s", report, expected)
+	}
+}
+
+func TestFailureReport(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+
+	deploymentMapping := &apimeta.RESTMapping{
+		Resource:         schema.GroupVersionResource{Group: "apps", Version: "v1", Resource: "deployments"},
+		GroupVersionKind: schema.GroupVersionKind{Group: "apps", Version: "v1", Kind: "Deployment"},
+		Scope:            apimeta.RESTScopeNamespace,
+	}
+	configMapMapping := &apimeta.RESTMapping{
+		Resource:         schema.GroupVersionResource{Version: "v1", Resource: "configmaps"},
+		GroupVersionKind: schema.GroupVersionKind{Version: "v1", Kind: "ConfigMap"},
+		Scope:            apimeta.RESTScopeNamespace,
+	}
+
+	// 12 deployments that are not ready, a config map that is Current and a
+	// deployment that doesn't exist
+	var objects []runtime.Object
+	var resources kube.ResourceList
+	for i := 0; i < 12; i++ {
+		name := fmt.Sprintf("web-%02d", i)
+		objects = append(objects, &unstructured.Unstructured{Object: map[string]interface{}{
+			"apiVersion": "apps/v1",
+			"kind":       "Deployment",
+			"metadata":   map[string]interface{}{"name": name, "namespace": "apps"},
+			"spec":       map[string]interface{}{"replicas": int64(1)},
+		}})
+		resources = append(resources, &resource.Info{Name: name, Namespace: "apps", Mapping: deploymentMapping})
+	}
+	objects = append(objects, &unstructured.Unstructured{Object: map[string]interface{}{
+		"apiVersion": "v1",
+		"kind":       "ConfigMap",
+		"metadata":   map[string]interface{}{"name": "config", "namespace": "apps"},
+	}})
+	resources = append(resources,
+		&resource.Info{Name: "config", Namespace: "apps", Mapping: configMapMapping},
+		&resource.Info{Name: "missing", Namespace: "apps", Mapping: deploymentMapping},
+	)
+	dynamicClient := dynamicfake.NewSimpleDynamicClient(runtime.NewScheme(), objects...)
+
+	// 7 events of the first deployment, the last one leaking a sensitive value
+	var events []runtime.Object
+	for i := 1; i <= 7; i++ {
+		message := fmt.Sprintf("event %d", i)
+		if i == 7 {
+			message = "password hunter2 rejected"
+		}
+		events = append(events, &corev1.Event{
+			ObjectMeta:     metav1.ObjectMeta{Name: fmt.Sprintf("web-00.%d", i), Namespace: "apps"},
+			InvolvedObject: corev1.ObjectReference{Kind: "Deployment", Name: "web-00", Namespace: "apps"},
+			Type:           corev1.EventTypeWarning,
+			Reason:         fmt.Sprintf("Reason%d", i),
+			Message:        message,
+			LastTimestamp:  metav1.NewTime(now.Add(time.Duration(i-10) * time.Minute)),
+		})
+	}
+	clientset := fake.NewClientset(events...)
+
+	setType := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name":  types.StringType,
+			"type":  types.StringType,
+			"value": types.StringType,
+		},
+	}
+	setSensitive, diags := types.ListValueFrom(ctx, setType, []setResourceModel{
+		{Name: types.StringValue("password"), Value: types.StringValue("hunter2"), Type: types.StringValue("auto")},
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	state := &HelmReleaseModel{SetSensitive: setSensitive, SetWO: types.ListNull(setType)}
+	sensitiveValues := failureSensitiveValues(ctx, state, state)
+
+	t.Run("report", func(t *testing.T) {
+		report := failureReport(ctx, dynamicClient, clientset, resources, false, sensitiveValues)
+
+		if !strings.HasPrefix(report, "Objects of the release that are not ready:\n\nDeployment apps/web-00: InProgress: updated replicas: 0/1\n") {
+			t.Errorf("unexpected beginning of the report:\n%s", report)
+		}
+		if n := strings.Count(report, ": InProgress: "); n != failureReportMaxObjects {
+			t.Errorf("expected %d reported objects, got %d:\n%s", failureReportMaxObjects, n, report)
+		}
+		if !strings.HasSuffix(report, "... and 2 more objects that are not ready") {
+			t.Errorf("expected the objects over the limit to be counted:\n%s", report)
+		}
+		for _, unexpected := range []string{"ConfigMap", "missing", "Reason1:", "Reason2:", "hunter2"} {
+			if strings.Contains(report, unexpected) {
+				t.Errorf("unexpected %q in the report:\n%s", unexpected, report)
+			}
+		}
+		for _, expected := range []string{"Warning Reason3: event 3", "Warning Reason7: password " + hashSensitiveValue("hunter2") + " rejected"} {
+			if !strings.Contains(report, expected) {
+				t.Errorf("expected %q in the report:\n%s", expected, report)
+			}
+		}
+	})
+
+	t.Run("nothing to report", func(t *testing.T) {
+		if report := failureReport(ctx, dynamicClient, clientset, resources[12:], false, sensitiveValues); report != "" {
+			t.Errorf("expected an empty report, got:\n%s", report)
+		}
+	})
+}
+
+func TestTruncateReport(t *testing.T) {
+	tests := map[string]struct {
+		report    string
+		maxLength int
+		expected  string
+	}{
+		"short": {
+			report:    "line 1\nline 2",
+			maxLength: 20,
+			expected:  "line 1\nline 2",
+		},
+		"line boundary": {
+			report:    "line 1\nline 2\nline 3",
+			maxLength: 16,
+			expected:  "line 1\nline 2\n... (truncated)",
+		},
+		"rune boundary": {
+			report:    "ééééé",
+			maxLength: 5,
+			expected:  "éé\n... (truncated)",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			truncated := truncateReport(tt.report, tt.maxLength)
+			if truncated != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, truncated)
+			}
+			if !utf8.ValidString(truncated) {
+				t.Errorf("expected valid UTF-8, got %q", truncated)
+			}
+		})
+	}
+}
+
+func TestContainerCrashed(t *testing.T) {
+	tests := map[string]struct {
+		status   corev1.ContainerStatus
+		crashed  bool
+		previous bool
+	}{
+		"running": {
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}}},
+		},
+		"completed": {
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Completed"}}},
+		},
+		"terminated with an error": {
+			status:  corev1.ContainerStatus{State: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 2}}},
+			crashed: true,
+		},
+		"restarted after being OOM killed": {
+			status: corev1.ContainerStatus{
+				RestartCount:         1,
+				State:                corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+				LastTerminationState: corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137}},
+			},
+			crashed:  true,
+			previous: true,
+		},
+		"image pull back-off": {
+			status: corev1.ContainerStatus{State: corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ImagePullBackOff"}}},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			crashed, previous := containerCrashed(tt.status)
+			if crashed != tt.crashed || previous != tt.previous {
+				t.Errorf("expected (%t, %t), got (%t, %t)", tt.crashed, tt.previous, crashed, previous)
+			}
+		})
+	}
+}
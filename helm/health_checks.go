@@ -0,0 +1,287 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/release"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// healthCheckInterval is the time between two evaluations of the health
+// checks of a release.
+var healthCheckInterval = 2 * time.Second
+
+// HealthCheckModel selects an object of a release and the condition it must
+// meet to be ready
+type HealthCheckModel struct {
+	APIVersion types.String `tfsdk:"api_version"`
+	Condition  types.String `tfsdk:"condition"`
+	JSONPath   types.String `tfsdk:"json_path"`
+	Kind       types.String `tfsdk:"kind"`
+	Name       types.String `tfsdk:"name"`
+	Namespace  types.String `tfsdk:"namespace"`
+	Status     types.String `tfsdk:"status"`
+	Value      types.String `tfsdk:"value"`
+}
+
+func healthCheckAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"api_version": types.StringType,
+		"condition":   types.StringType,
+		"json_path":   types.StringType,
+		"kind":        types.StringType,
+		"name":        types.StringType,
+		"namespace":   types.StringType,
+		"status":      types.StringType,
+		"value":       types.StringType,
+	}
+}
+
+// healthCheck is a health check along with the outcome of its last evaluation
+type healthCheck struct {
+	index     int
+	model     HealthCheckModel
+	namespace string
+	reason    string
+}
+
+func (c healthCheck) object() string {
+	if c.namespace == "" {
+		return fmt.Sprintf("%s %s (%s)", c.model.Kind.ValueString(), c.model.Name.ValueString(), c.model.APIVersion.ValueString())
+	}
+	return fmt.Sprintf("%s %s/%s (%s)", c.model.Kind.ValueString(), c.namespace, c.model.Name.ValueString(), c.model.APIVersion.ValueString())
+}
+
+func (c healthCheck) condition() string {
+	if !c.model.JSONPath.IsNull() {
+		return fmt.Sprintf("%s == %q", c.model.JSONPath.ValueString(), c.model.Value.ValueString())
+	}
+	return fmt.Sprintf("condition %s=%s", c.model.Condition.ValueString(), c.model.expectedStatus())
+}
+
+func (m HealthCheckModel) expectedStatus() string {
+	if status := m.Status.ValueString(); status != "" {
+		return status
+	}
+	return string(metav1.ConditionTrue)
+}
+
+// validateHealthChecks checks that the JSONPath expressions and the API
+// versions of the health checks are valid when planning, rather than letting
+// the checks poll until the timeout after the release is deployed.
+func validateHealthChecks(ctx context.Context, healthChecks types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if healthChecks.IsNull() || healthChecks.IsUnknown() {
+		return diags
+	}
+
+	var models []HealthCheckModel
+	diags.Append(healthChecks.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	for i, model := range models {
+		if !model.JSONPath.IsNull() && !model.JSONPath.IsUnknown() {
+			if _, err := parseOutputJSONPath(model.JSONPath.ValueString()); err != nil {
+				diags.AddAttributeError(
+					path.Root("health_checks").AtListIndex(i).AtName("json_path"),
+					"Invalid JSONPath",
+					fmt.Sprintf("Unable to parse the JSONPath of health check %d: %s", i, err),
+				)
+			}
+		}
+		if !model.APIVersion.IsUnknown() {
+			if _, err := schema.ParseGroupVersion(model.APIVersion.ValueString()); err != nil {
+				diags.AddAttributeError(
+					path.Root("health_checks").AtListIndex(i).AtName("api_version"),
+					"Invalid API version",
+					fmt.Sprintf("Unable to parse the API version of health check %d: %s", i, err),
+				)
+			}
+		}
+	}
+	return diags
+}
+
+// runHealthChecks polls the objects selected by the health_checks of a
+// release until they are all ready, or the context is done. The checks that
+// never passed are reported as errors along with the reason of their last
+// failure, the first one along with the failure report of the release.
+func runHealthChecks(ctx context.Context, actionConfig *action.Configuration, plan *HelmReleaseModel, r *release.Release, sensitiveValues map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	name := plan.Name.ValueString()
+
+	var models []HealthCheckModel
+	diags.Append(plan.HealthChecks.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	kc, err := getKubeClient(actionConfig)
+	if err != nil {
+		diags.AddError("Error running health checks", fmt.Sprintf("Unable to get the kubernetes client of release %q: %s", name, err))
+		return diags
+	}
+	dynamicClient, err := kc.Factory.DynamicClient()
+	if err != nil {
+		diags.AddError("Error running health checks", fmt.Sprintf("Unable to get the kubernetes client of release %q: %s", name, err))
+		return diags
+	}
+	mapper, err := actionConfig.RESTClientGetter.ToRESTMapper()
+	if err != nil {
+		diags.AddError("Error running health checks", fmt.Sprintf("Unable to get the REST mapper of release %q: %s", name, err))
+		return diags
+	}
+
+	pending := make([]healthCheck, 0, len(models))
+	for i, model := range models {
+		check := healthCheck{index: i, model: model, namespace: model.Namespace.ValueString()}
+		if check.namespace == "" {
+			check.namespace = plan.Namespace.ValueString()
+		}
+		pending = append(pending, check)
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Running %d health checks of release %s", len(pending), name))
+	for {
+		var notReady []healthCheck
+		for _, check := range pending {
+			gv, err := schema.ParseGroupVersion(check.model.APIVersion.ValueString())
+			if err != nil {
+				diags.AddAttributeError(
+					path.Root("health_checks").AtListIndex(check.index).AtName("api_version"),
+					"Invalid health check",
+					fmt.Sprintf("Unable to parse the API version of %s: %s", check.object(), err),
+				)
+				continue
+			}
+			mapping, err := mapper.RESTMapping(gv.WithKind(check.model.Kind.ValueString()).GroupKind(), gv.Version)
+			if err != nil {
+				// The kind may be defined by a CRD of the release that is not
+				// discovered yet
+				if apimeta.IsNoMatchError(err) {
+					if resettable, ok := mapper.(apimeta.ResettableRESTMapper); ok {
+						resettable.Reset()
+					}
+				}
+				if ctx.Err() == nil {
+					check.reason = err.Error()
+				}
+				notReady = append(notReady, check)
+				continue
+			}
+
+			resource := dynamicClient.Resource(mapping.Resource)
+			var object *unstructured.Unstructured
+			if mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+				object, err = resource.Namespace(check.namespace).Get(ctx, check.model.Name.ValueString(), metav1.GetOptions{})
+			} else {
+				check.namespace = ""
+				object, err = resource.Get(ctx, check.model.Name.ValueString(), metav1.GetOptions{})
+			}
+			if err != nil {
+				// Keep the reason of the previous evaluation when the
+				// request is cancelled by the timeout
+				if ctx.Err() == nil {
+					check.reason = err.Error()
+				}
+				notReady = append(notReady, check)
+				continue
+			}
+
+			ready, reason := evaluateHealthCheck(check.model, object.Object)
+			if !ready {
+				check.reason = reason
+				notReady = append(notReady, check)
+				continue
+			}
+			tflog.Debug(ctx, fmt.Sprintf("Health check of %s passed", check.object()))
+		}
+		if diags.HasError() {
+			return diags
+		}
+		if len(notReady) == 0 {
+			tflog.Debug(ctx, fmt.Sprintf("Health checks of release %s passed", name))
+			return diags
+		}
+		pending = notReady
+
+		select {
+		case <-ctx.Done():
+			report := releaseFailureReport(ctx, actionConfig, plan, r, sensitiveValues)
+			for i, check := range pending {
+				detail := fmt.Sprintf("Helm release %q: %s never met %s: %s", name, check.object(), check.condition(), check.reason)
+				if i == 0 {
+					detail = withFailureReport(detail, report)
+				}
+				diags.AddAttributeError(path.Root("health_checks").AtListIndex(check.index), "Helm release health check failed", detail)
+			}
+			return diags
+		case <-time.After(healthCheckInterval):
+		}
+	}
+}
+
+// evaluateHealthCheck checks whether an object meets the condition of a
+// health check, and if not, returns the reason why. Status conditions and
+// statuses that were not observed for the current generation of the object
+// are not taken into account.
+func evaluateHealthCheck(check HealthCheckModel, object map[string]interface{}) (bool, string) {
+	generation, _, _ := unstructured.NestedInt64(object, "metadata", "generation")
+	observed, found, _ := unstructured.NestedInt64(object, "status", "observedGeneration")
+	if found && observed < generation {
+		return false, fmt.Sprintf("the status of generation %d is not observed yet", generation)
+	}
+
+	if !check.JSONPath.IsNull() {
+		value, err := evaluateOutput(check.JSONPath.ValueString(), object)
+		if err != nil {
+			return false, err.Error()
+		}
+		if value != check.Value.ValueString() {
+			return false, fmt.Sprintf("the value is %q", value)
+		}
+		return true, ""
+	}
+
+	conditionType := check.Condition.ValueString()
+	expected := check.expectedStatus()
+	conditions, _, _ := unstructured.NestedSlice(object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if !ok || condition["type"] != conditionType {
+			continue
+		}
+		if observed, ok := condition["observedGeneration"].(int64); ok && observed < generation {
+			return false, fmt.Sprintf("the condition of generation %d is not observed yet", generation)
+		}
+		status := fmt.Sprint(condition["status"])
+		if strings.EqualFold(status, expected) {
+			return true, ""
+		}
+		reason := fmt.Sprintf("the condition is %s", status)
+		if r, ok := condition["reason"].(string); ok && r != "" {
+			reason = fmt.Sprintf("%s (%s)", reason, r)
+		}
+		if m, ok := condition["message"].(string); ok && m != "" {
+			reason = fmt.Sprintf("%s: %s", reason, m)
+		}
+		return false, reason
+	}
+	return false, "the condition is not set"
+}
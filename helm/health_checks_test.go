@@ -0,0 +1,219 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+)
+
+func TestEvaluateHealthCheck(t *testing.T) {
+	certificate := func(generation, observed int64, status, reason string) map[string]interface{} {
+		return map[string]interface{}{
+			"apiVersion": "cert-manager.io/v1",
+			"kind":       "Certificate",
+			"metadata":   map[string]interface{}{"name": "web", "generation": generation},
+			"status": map[string]interface{}{
+				"conditions": []interface{}{
+					map[string]interface{}{
+						"type":               "Ready",
+						"status":             status,
+						"reason":             reason,
+						"message":            "Certificate is " + reason,
+						"observedGeneration": observed,
+					},
+				},
+			},
+		}
+	}
+	rollout := map[string]interface{}{
+		"apiVersion": "argoproj.io/v1alpha1",
+		"kind":       "Rollout",
+		"metadata":   map[string]interface{}{"name": "web", "generation": int64(3)},
+		"status": map[string]interface{}{
+			"observedGeneration": int64(3),
+			"phase":              "Progressing",
+		},
+	}
+	condition := func(conditionType, status string) HealthCheckModel {
+		check := HealthCheckModel{
+			Condition: types.StringValue(conditionType),
+			Status:    types.StringNull(),
+			JSONPath:  types.StringNull(),
+			Value:     types.StringNull(),
+		}
+		if status != "" {
+			check.Status = types.StringValue(status)
+		}
+		return check
+	}
+	jsonPath := func(expression, value string) HealthCheckModel {
+		return HealthCheckModel{
+			Condition: types.StringNull(),
+			Status:    types.StringNull(),
+			JSONPath:  types.StringValue(expression),
+			Value:     types.StringValue(value),
+		}
+	}
+
+	tests := map[string]struct {
+		check  HealthCheckModel
+		object map[string]interface{}
+		ready  bool
+		reason string
+	}{
+		"condition met": {
+			check:  condition("Ready", ""),
+			object: certificate(1, 1, "True", "Ready"),
+			ready:  true,
+		},
+		"condition with an expected status": {
+			check:  condition("Ready", "false"),
+			object: certificate(1, 1, "False", "Issuing"),
+			ready:  true,
+		},
+		"condition not met": {
+			check:  condition("Ready", ""),
+			object: certificate(1, 1, "False", "Issuing"),
+			reason: "the condition is False (Issuing): Certificate is Issuing",
+		},
+		"condition of a previous generation": {
+			check:  condition("Ready", ""),
+			object: certificate(2, 1, "True", "Ready"),
+			reason: "the condition of generation 2 is not observed yet",
+		},
+		"condition not set": {
+			check:  condition("Issued", ""),
+			object: certificate(1, 1, "True", "Ready"),
+			reason: "the condition is not set",
+		},
+		"json_path met": {
+			check:  jsonPath(".status.phase", "Progressing"),
+			object: rollout,
+			ready:  true,
+		},
+		"json_path not met": {
+			check:  jsonPath("{.status.phase}", "Healthy"),
+			object: rollout,
+			reason: `the value is "Progressing"`,
+		},
+		"json_path not found": {
+			check:  jsonPath("{.status.stableRS}", "web-1"),
+			object: rollout,
+			reason: "stableRS is not found",
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			ready, reason := evaluateHealthCheck(tt.check, tt.object)
+			if ready != tt.ready || reason != tt.reason {
+				t.Errorf("expected (%t, %q), got (%t, %q)", tt.ready, tt.reason, ready, reason)
+			}
+		})
+	}
+}
+
+func TestValidateHealthChecks(t *testing.T) {
+	check := func(apiVersion, jsonPath string) HealthCheckModel {
+		m := HealthCheckModel{
+			APIVersion: types.StringValue(apiVersion),
+			Condition:  types.StringNull(),
+			JSONPath:   types.StringNull(),
+			Kind:       types.StringValue("Rollout"),
+			Name:       types.StringValue("web"),
+			Namespace:  types.StringNull(),
+			Status:     types.StringNull(),
+			Value:      types.StringNull(),
+		}
+		if jsonPath != "" {
+			m.JSONPath = types.StringValue(jsonPath)
+			m.Value = types.StringValue("Healthy")
+		}
+		return m
+	}
+	healthChecks, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: healthCheckAttrTypes()}, []HealthCheckModel{
+		check("argoproj.io/v1alpha1", "{.status.phase}"),
+		check("argoproj.io/v1alpha1", "{.status.phase"),
+		check("argoproj.io/v1alpha1/v2", ""),
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	diags = validateHealthChecks(context.Background(), healthChecks)
+	if diags.ErrorsCount() != 2 {
+		t.Fatalf("expected two errors, got %v", diags)
+	}
+	for i, expected := range []path.Path{
+		path.Root("health_checks").AtListIndex(1).AtName("json_path"),
+		path.Root("health_checks").AtListIndex(2).AtName("api_version"),
+	} {
+		withPath, ok := diags[i].(diag.DiagnosticWithPath)
+		if !ok || !withPath.Path().Equal(expected) {
+			t.Errorf("expected the error to be attributed to %s, got %v", expected, diags[i])
+		}
+	}
+}
+
+func TestAccResourceRelease_healthChecks(t *testing.T) {
+	name := randName("health-checks")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigHealthChecks(testResourceName, namespace, name, "1"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "status", "deployed"),
+					resource.TestCheckResourceAttr("helm_release.test", "health_checks.#", "2"),
+				),
+			},
+			{
+				Config:      testAccHelmReleaseConfigHealthChecks(testResourceName, namespace, name, "5"),
+				ExpectError: regexp.MustCompile(fmt.Sprintf(`Deployment %s/%s-test-chart \(apps/v1\) never met\s+\{.status.readyReplicas\} == "5"`, namespace, name)),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigHealthChecks(resource, ns, name, readyReplicas string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+
+			health_checks = [
+				{
+					api_version = "apps/v1"
+					kind        = "Deployment"
+					name        = "%[2]s-test-chart"
+					condition   = "Available"
+				},
+				{
+					api_version = "apps/v1"
+					kind        = "Deployment"
+					name        = "%[2]s-test-chart"
+					json_path   = "{.status.readyReplicas}"
+					value       = %[5]q
+				},
+			]
+
+			timeouts = {
+				update = "30s"
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, readyReplicas)
+}
@@ -0,0 +1,559 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	pathpkg "path"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+const (
+	waitStrategyHelm    = "helm"
+	waitStrategyKstatus = "kstatus"
+)
+
+// kstatusInterval is the time between two evaluations of the objects of a
+// release by the kstatus wait strategy.
+var kstatusInterval = 2 * time.Second
+
+// objectStatus is the status of an object as computed by kstatus
+type objectStatus string
+
+const (
+	statusCurrent     objectStatus = "Current"
+	statusInProgress  objectStatus = "InProgress"
+	statusFailed      objectStatus = "Failed"
+	statusTerminating objectStatus = "Terminating"
+	statusNotFound    objectStatus = "NotFound"
+	statusUnknown     objectStatus = "Unknown"
+)
+
+// useHelmWait returns whether Helm itself waits for the objects of a release,
+// rather than the provider with the kstatus wait strategy.
+func useHelmWait(plan *HelmReleaseModel) bool {
+	return plan.Wait.ValueBool() && plan.WaitStrategy.ValueString() != waitStrategyKstatus
+}
+
+// useKstatusWait returns whether the objects of a release are waited for with
+// the kstatus wait strategy.
+func useKstatusWait(plan *HelmReleaseModel) bool {
+	return plan.Wait.ValueBool() && plan.WaitStrategy.ValueString() == waitStrategyKstatus
+}
+
+// validateWaitStrategy checks that the kstatus wait strategy is not combined
+// with atomic, which makes Helm wait for the release on its own and roll it
+// back regardless of the outcome of the kstatus wait.
+func validateWaitStrategy(plan *HelmReleaseModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if plan.WaitStrategy.ValueString() == waitStrategyKstatus && plan.Atomic.ValueBool() {
+		diags.AddAttributeError(
+			path.Root("wait_strategy"),
+			"Invalid wait strategy",
+			"The kstatus wait strategy can't be combined with atomic, as atomic always waits for the release with the wait of Helm. Use rollback_on_failure to roll back upgrades that fail the kstatus wait.",
+		)
+	}
+	return diags
+}
+
+// validateWaitStrategyHooks warns when the kstatus wait strategy is used
+// with a chart that has post-install or post-upgrade hooks. As the wait of
+// Helm is turned off, Helm runs these hooks as soon as the objects of the
+// release are created, before the kstatus wait, rather than once they are
+// ready. The hooks are found in the templates of the chart and of its
+// subcharts.
+func validateWaitStrategyHooks(plan *HelmReleaseModel, c *chart.Chart) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if !useKstatusWait(plan) || plan.DisableWebhooks.ValueBool() {
+		return diags
+	}
+
+	templates := postDeployHookTemplates(c, "")
+	if len(templates) == 0 {
+		return diags
+	}
+	diags.AddAttributeWarning(
+		path.Root("wait_strategy"),
+		"Hooks run before the kstatus wait",
+		fmt.Sprintf("Chart %s has post-install or post-upgrade hooks. With the kstatus wait strategy, Helm runs them as soon as the objects of the release are created, before they are ready, while with the wait of Helm they run once the release is ready. Hooks that need the workloads of the release to be ready, such as database migration Jobs, may fail:\n  %s",
+			c.Name(), strings.Join(templates, "\n  ")),
+	)
+	return diags
+}
+
+// postDeployHookTemplates returns the templates of a chart and of its
+// subcharts that declare post-install or post-upgrade hooks, prefixed with
+// the path of the chart
+func postDeployHookTemplates(c *chart.Chart, prefix string) []string {
+	prefix = pathpkg.Join(prefix, c.Name())
+	var templates []string
+	for _, t := range c.Templates {
+		for _, line := range strings.Split(string(t.Data), "\n") {
+			if !strings.Contains(line, release.HookAnnotation+":") && !strings.Contains(line, release.HookAnnotation+`":`) {
+				continue
+			}
+			if strings.Contains(line, string(release.HookPostInstall)) || strings.Contains(line, string(release.HookPostUpgrade)) {
+				templates = append(templates, pathpkg.Join(prefix, t.Name))
+				break
+			}
+		}
+	}
+	for _, subchart := range c.Dependencies() {
+		templates = append(templates, postDeployHookTemplates(subchart, pathpkg.Join(prefix, "charts"))...)
+	}
+	return templates
+}
+
+// releaseObject is an object of a release along with its last computed status
+type releaseObject struct {
+	info    *resource.Info
+	status  objectStatus
+	message string
+}
+
+func (o releaseObject) String() string {
+	kind := o.info.Mapping.GroupVersionKind.Kind
+	if o.info.Namespace == "" {
+		return fmt.Sprintf("%s %s", kind, o.info.Name)
+	}
+	return fmt.Sprintf("%s %s/%s", kind, o.info.Namespace, o.info.Name)
+}
+
+// waitForReleaseObjects polls every object of the manifest of a release until
+// they are all Current, until one of them is Failed, or until the timeout of
+// the release expires. Otherwise, the last status of every object is reported
+// in an error along with the failure report of the release.
+func waitForReleaseObjects(ctx context.Context, actionConfig *action.Configuration, plan *HelmReleaseModel, r *release.Release, sensitiveValues map[string]string) diag.Diagnostics {
+	var diags diag.Diagnostics
+	name := plan.Name.ValueString()
+
+	resources, err := buildReleaseResources(actionConfig, r)
+	if err != nil {
+		diags.AddError("Error waiting for release", fmt.Sprintf("Unable to build the objects of release %q: %s", name, err))
+		return diags
+	}
+	kc, err := getKubeClient(actionConfig)
+	if err != nil {
+		diags.AddError("Error waiting for release", fmt.Sprintf("Unable to get the kubernetes client of release %q: %s", name, err))
+		return diags
+	}
+	dynamicClient, err := kc.Factory.DynamicClient()
+	if err != nil {
+		diags.AddError("Error waiting for release", fmt.Sprintf("Unable to get the kubernetes client of release %q: %s", name, err))
+		return diags
+	}
+
+	objects := make([]releaseObject, 0, len(resources))
+	for _, info := range resources {
+		objects = append(objects, releaseObject{info: info, status: statusUnknown})
+	}
+
+	timeout := time.Duration(plan.Timeout.ValueInt64()) * time.Second
+	waitCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	waitForJobs := plan.WaitForJobs.ValueBool()
+	tflog.Debug(ctx, fmt.Sprintf("Waiting for %d objects of release %s to be Current", len(objects), name))
+	for {
+		current, failed := 0, 0
+		for i := range objects {
+			object := &objects[i]
+			client := dynamicClient.Resource(object.info.Mapping.Resource)
+			var live *unstructured.Unstructured
+			if object.info.Mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+				live, err = client.Namespace(object.info.Namespace).Get(waitCtx, object.info.Name, metav1.GetOptions{})
+			} else {
+				live, err = client.Get(waitCtx, object.info.Name, metav1.GetOptions{})
+			}
+
+			status, message := statusUnknown, ""
+			switch {
+			case apierrors.IsNotFound(err):
+				status, message = statusNotFound, "the object does not exist"
+			case err != nil:
+				// Keep the status of the previous evaluation when the request
+				// is cancelled by the timeout
+				if waitCtx.Err() != nil {
+					status, message = object.status, object.message
+					break
+				}
+				message = err.Error()
+			default:
+				status, message = computeObjectStatus(live.Object, waitForJobs)
+			}
+			if status != object.status {
+				tflog.Debug(ctx, fmt.Sprintf("%s is %s", object, status), map[string]interface{}{"message": message})
+			}
+			object.status, object.message = status, message
+			switch status {
+			case statusCurrent:
+				current++
+			case statusFailed:
+				failed++
+			}
+		}
+		tflog.Debug(ctx, fmt.Sprintf("%d of %d objects of release %s are Current", current, len(objects), name))
+		if current == len(objects) {
+			return diags
+		}
+
+		// Failed objects don't recover without a change of their spec, so
+		// there is no point waiting for the timeout
+		if failed > 0 {
+			diags.AddError(
+				"Helm release objects not ready",
+				withFailureReport(
+					fmt.Sprintf("Helm release %q: %d of %d objects failed:%s", name, failed, len(objects), objectsSummary(objects)),
+					releaseFailureReport(ctx, actionConfig, plan, r, sensitiveValues),
+				),
+			)
+			return diags
+		}
+
+		select {
+		case <-waitCtx.Done():
+			diags.AddError(
+				"Helm release objects not ready",
+				withFailureReport(
+					fmt.Sprintf("Helm release %q: %d of %d objects were not Current after %s:%s", name, len(objects)-current, len(objects), timeout, objectsSummary(objects)),
+					releaseFailureReport(ctx, actionConfig, plan, r, sensitiveValues),
+				),
+			)
+			return diags
+		case <-time.After(kstatusInterval):
+		}
+	}
+}
+
+// objectsSummary lists the last status of the objects of a release
+func objectsSummary(objects []releaseObject) string {
+	var summary strings.Builder
+	for _, object := range objects {
+		fmt.Fprintf(&summary, "\n  %s: %s", object, object.status)
+		if object.message != "" {
+			fmt.Fprintf(&summary, ": %s", object.message)
+		}
+	}
+	return summary.String()
+}
+
+// failRelease marks a release whose objects didn't become ready as failed,
+// as Helm does when its own wait fails, so that it is rolled back or
+// upgraded like any other failed release.
+func failRelease(actionConfig *action.Configuration, r *release.Release) diag.Diagnostics {
+	var diags diag.Diagnostics
+	r.SetStatus(release.StatusFailed, fmt.Sprintf("Release %q failed: its objects did not become ready", r.Name))
+	if err := actionConfig.Releases.Update(r); err != nil {
+		diags.AddError("Error updating release", fmt.Sprintf("Unable to mark Helm release %q as failed: %s", r.Name, err))
+	}
+	return diags
+}
+
+// computeObjectStatus computes the status of an object following the rules
+// of kstatus: objects whose spec is not observed yet are InProgress, the
+// workloads and the built-in kinds with a well-known status are checked
+// field by field, and other kinds are checked through the Stalled,
+// Reconciling and Ready conditions. It returns the status along with a
+// message explaining it.
+func computeObjectStatus(object map[string]interface{}, waitForJobs bool) (objectStatus, string) {
+	if _, found, _ := unstructured.NestedFieldNoCopy(object, "metadata", "deletionTimestamp"); found {
+		return statusTerminating, "the object is being deleted"
+	}
+
+	generation, _, _ := unstructured.NestedInt64(object, "metadata", "generation")
+	observed, found, _ := unstructured.NestedInt64(object, "status", "observedGeneration")
+	if found && observed < generation {
+		return statusInProgress, fmt.Sprintf("generation %d is not observed yet", generation)
+	}
+
+	apiVersion, _ := object["apiVersion"].(string)
+	kind, _ := object["kind"].(string)
+	switch schema.FromAPIVersionAndKind(apiVersion, kind).GroupKind().String() {
+	case "Deployment.apps":
+		return deploymentStatus(object)
+	case "StatefulSet.apps":
+		return statefulSetStatus(object)
+	case "DaemonSet.apps":
+		return daemonSetStatus(object)
+	case "ReplicaSet.apps":
+		return replicaSetStatus(object)
+	case "PodDisruptionBudget.policy":
+		return podDisruptionBudgetStatus(object)
+	case "Job.batch":
+		return jobStatus(object, waitForJobs)
+	case "Pod":
+		return podStatus(object)
+	case "PersistentVolumeClaim":
+		return persistentVolumeClaimStatus(object)
+	case "Service":
+		return serviceStatus(object)
+	case "CustomResourceDefinition.apiextensions.k8s.io":
+		return customResourceDefinitionStatus(object)
+	}
+	return genericStatus(object)
+}
+
+func deploymentStatus(object map[string]interface{}) (objectStatus, string) {
+	if progressing := findCondition(object, "Progressing"); progressing != nil && progressing["reason"] == "ProgressDeadlineExceeded" {
+		return statusFailed, conditionMessage(progressing, "the progress deadline is exceeded")
+	}
+
+	replicas := specReplicas(object)
+	statusReplicas := statusInt64(object, "replicas")
+	updated := statusInt64(object, "updatedReplicas")
+	ready := statusInt64(object, "readyReplicas")
+	available := statusInt64(object, "availableReplicas")
+	switch {
+	case updated < replicas:
+		return statusInProgress, fmt.Sprintf("updated replicas: %d/%d", updated, replicas)
+	case statusReplicas > updated:
+		return statusInProgress, fmt.Sprintf("pending termination: %d", statusReplicas-updated)
+	case available < updated:
+		return statusInProgress, fmt.Sprintf("available replicas: %d/%d", available, updated)
+	case ready < replicas:
+		return statusInProgress, fmt.Sprintf("ready replicas: %d/%d", ready, replicas)
+	}
+	if progressing := findCondition(object, "Progressing"); progressing != nil && progressing["reason"] != "NewReplicaSetAvailable" {
+		return statusInProgress, conditionMessage(progressing, "the rollout is in progress")
+	}
+	if condition := findCondition(object, "Available"); condition != nil && condition["status"] != string(metav1.ConditionTrue) {
+		return statusInProgress, conditionMessage(condition, "the deployment is not available")
+	}
+	return statusCurrent, fmt.Sprintf("ready replicas: %d/%d", ready, replicas)
+}
+
+func statefulSetStatus(object map[string]interface{}) (objectStatus, string) {
+	strategy, _, _ := unstructured.NestedString(object, "spec", "updateStrategy", "type")
+	if strategy == "OnDelete" {
+		return statusCurrent, "the update strategy is OnDelete"
+	}
+
+	replicas := specReplicas(object)
+	ready := statusInt64(object, "readyReplicas")
+	current := statusInt64(object, "currentReplicas")
+	updated := statusInt64(object, "updatedReplicas")
+	if ready < replicas {
+		return statusInProgress, fmt.Sprintf("ready replicas: %d/%d", ready, replicas)
+	}
+	if partition, found, _ := unstructured.NestedInt64(object, "spec", "updateStrategy", "rollingUpdate", "partition"); found && partition > 0 {
+		if expected := replicas - partition; updated < expected {
+			return statusInProgress, fmt.Sprintf("updated replicas of the partition: %d/%d", updated, expected)
+		}
+		return statusCurrent, "the partition is rolled out"
+	}
+	currentRevision, _, _ := unstructured.NestedString(object, "status", "currentRevision")
+	updateRevision, _, _ := unstructured.NestedString(object, "status", "updateRevision")
+	if currentRevision != updateRevision {
+		return statusInProgress, fmt.Sprintf("updated replicas: %d/%d", updated, replicas)
+	}
+	if current < replicas {
+		return statusInProgress, fmt.Sprintf("current replicas: %d/%d", current, replicas)
+	}
+	return statusCurrent, fmt.Sprintf("ready replicas: %d/%d", ready, replicas)
+}
+
+func daemonSetStatus(object map[string]interface{}) (objectStatus, string) {
+	desired := statusInt64(object, "desiredNumberScheduled")
+	scheduled := statusInt64(object, "currentNumberScheduled")
+	updated := statusInt64(object, "updatedNumberScheduled")
+	available := statusInt64(object, "numberAvailable")
+	ready := statusInt64(object, "numberReady")
+	switch {
+	case scheduled < desired:
+		return statusInProgress, fmt.Sprintf("scheduled pods: %d/%d", scheduled, desired)
+	case updated < desired:
+		return statusInProgress, fmt.Sprintf("updated pods: %d/%d", updated, desired)
+	case available < desired:
+		return statusInProgress, fmt.Sprintf("available pods: %d/%d", available, desired)
+	case ready < desired:
+		return statusInProgress, fmt.Sprintf("ready pods: %d/%d", ready, desired)
+	}
+	return statusCurrent, fmt.Sprintf("ready pods: %d/%d", ready, desired)
+}
+
+func replicaSetStatus(object map[string]interface{}) (objectStatus, string) {
+	if failure := findCondition(object, "ReplicaFailure"); failure != nil && failure["status"] == string(metav1.ConditionTrue) {
+		return statusInProgress, conditionMessage(failure, "replicas failed to be created")
+	}
+
+	replicas := specReplicas(object)
+	labeled := statusInt64(object, "fullyLabeledReplicas")
+	available := statusInt64(object, "availableReplicas")
+	ready := statusInt64(object, "readyReplicas")
+	switch {
+	case labeled < replicas:
+		return statusInProgress, fmt.Sprintf("labeled replicas: %d/%d", labeled, replicas)
+	case available < replicas:
+		return statusInProgress, fmt.Sprintf("available replicas: %d/%d", available, replicas)
+	case ready < replicas:
+		return statusInProgress, fmt.Sprintf("ready replicas: %d/%d", ready, replicas)
+	}
+	return statusCurrent, fmt.Sprintf("ready replicas: %d/%d", ready, replicas)
+}
+
+func podDisruptionBudgetStatus(object map[string]interface{}) (objectStatus, string) {
+	healthy := statusInt64(object, "currentHealthy")
+	desired := statusInt64(object, "desiredHealthy")
+	if healthy < desired {
+		return statusInProgress, fmt.Sprintf("healthy pods: %d/%d", healthy, desired)
+	}
+	return statusCurrent, fmt.Sprintf("healthy pods: %d/%d", healthy, desired)
+}
+
+func jobStatus(object map[string]interface{}, waitForJobs bool) (objectStatus, string) {
+	if failed := findCondition(object, "Failed"); failed != nil && failed["status"] == string(metav1.ConditionTrue) {
+		return statusFailed, conditionMessage(failed, "the job failed")
+	}
+	if complete := findCondition(object, "Complete"); complete != nil && complete["status"] == string(metav1.ConditionTrue) {
+		return statusCurrent, "the job is complete"
+	}
+
+	succeeded := statusInt64(object, "succeeded")
+	completions, found, _ := unstructured.NestedInt64(object, "spec", "completions")
+	if !found {
+		completions = 1
+	}
+	if waitForJobs {
+		return statusInProgress, fmt.Sprintf("succeeded pods: %d/%d", succeeded, completions)
+	}
+	if _, started, _ := unstructured.NestedString(object, "status", "startTime"); started {
+		return statusCurrent, "the job is started"
+	}
+	return statusInProgress, "the job is not started yet"
+}
+
+func podStatus(object map[string]interface{}) (objectStatus, string) {
+	phase, _, _ := unstructured.NestedString(object, "status", "phase")
+	switch phase {
+	case string(corev1.PodSucceeded):
+		return statusCurrent, "the pod succeeded"
+	case string(corev1.PodFailed):
+		reason, _, _ := unstructured.NestedString(object, "status", "reason")
+		if reason == "" {
+			return statusFailed, "the pod failed"
+		}
+		return statusFailed, fmt.Sprintf("the pod failed: %s", reason)
+	}
+
+	containers, _, _ := unstructured.NestedSlice(object, "status", "containerStatuses")
+	for _, c := range containers {
+		container, ok := c.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		reason, _, _ := unstructured.NestedString(container, "state", "waiting", "reason")
+		if reason == "CrashLoopBackOff" {
+			return statusFailed, fmt.Sprintf("container %v is in CrashLoopBackOff", container["name"])
+		}
+	}
+
+	if ready := findCondition(object, "Ready"); ready != nil && ready["status"] == string(metav1.ConditionTrue) {
+		return statusCurrent, "the pod is ready"
+	}
+	if phase == "" {
+		phase = "Pending"
+	}
+	return statusInProgress, fmt.Sprintf("the pod is %s", phase)
+}
+
+func persistentVolumeClaimStatus(object map[string]interface{}) (objectStatus, string) {
+	phase, _, _ := unstructured.NestedString(object, "status", "phase")
+	if phase != "Bound" {
+		return statusInProgress, "the claim is not bound yet"
+	}
+	return statusCurrent, "the claim is bound"
+}
+
+func serviceStatus(object map[string]interface{}) (objectStatus, string) {
+	serviceType, _, _ := unstructured.NestedString(object, "spec", "type")
+	if serviceType != "LoadBalancer" {
+		return statusCurrent, ""
+	}
+	ingress, _, _ := unstructured.NestedSlice(object, "status", "loadBalancer", "ingress")
+	if len(ingress) == 0 {
+		return statusInProgress, "the load balancer is not provisioned yet"
+	}
+	return statusCurrent, "the load balancer is provisioned"
+}
+
+func customResourceDefinitionStatus(object map[string]interface{}) (objectStatus, string) {
+	if names := findCondition(object, "NamesAccepted"); names != nil && names["status"] == string(metav1.ConditionFalse) {
+		return statusFailed, conditionMessage(names, "the names are not accepted")
+	}
+	if established := findCondition(object, "Established"); established != nil && established["status"] == string(metav1.ConditionTrue) {
+		return statusCurrent, "the definition is established"
+	}
+	return statusInProgress, "the definition is not established yet"
+}
+
+func genericStatus(object map[string]interface{}) (objectStatus, string) {
+	if stalled := findCondition(object, "Stalled"); stalled != nil && stalled["status"] == string(metav1.ConditionTrue) {
+		return statusFailed, conditionMessage(stalled, "the object is stalled")
+	}
+	if reconciling := findCondition(object, "Reconciling"); reconciling != nil && reconciling["status"] == string(metav1.ConditionTrue) {
+		return statusInProgress, conditionMessage(reconciling, "the object is reconciling")
+	}
+	if ready := findCondition(object, "Ready"); ready != nil && ready["status"] == string(metav1.ConditionFalse) {
+		return statusInProgress, conditionMessage(ready, "the object is not ready")
+	}
+	return statusCurrent, ""
+}
+
+// findCondition returns the status condition of an object with the given
+// type, or nil if it is not set
+func findCondition(object map[string]interface{}, conditionType string) map[string]interface{} {
+	conditions, _, _ := unstructured.NestedSlice(object, "status", "conditions")
+	for _, c := range conditions {
+		condition, ok := c.(map[string]interface{})
+		if ok && condition["type"] == conditionType {
+			return condition
+		}
+	}
+	return nil
+}
+
+// conditionMessage describes a condition by its reason and message, falling
+// back to the given description when the condition has neither
+func conditionMessage(condition map[string]interface{}, fallback string) string {
+	reason, _ := condition["reason"].(string)
+	message, _ := condition["message"].(string)
+	switch {
+	case reason != "" && message != "":
+		return fmt.Sprintf("%s: %s", reason, message)
+	case message != "":
+		return message
+	case reason != "":
+		return reason
+	}
+	return fallback
+}
+
+func specReplicas(object map[string]interface{}) int64 {
+	replicas, found, _ := unstructured.NestedInt64(object, "spec", "replicas")
+	if !found {
+		return 1
+	}
+	return replicas
+}
+
+func statusInt64(object map[string]interface{}, field string) int64 {
+	value, _, _ := unstructured.NestedInt64(object, "status", field)
+	return value
+}
@@ -0,0 +1,319 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"helm.sh/helm/v3/pkg/chart"
+)
+
+func TestComputeObjectStatus(t *testing.T) {
+	object := func(apiVersion, kind string, generation int64, spec, status map[string]interface{}) map[string]interface{} {
+		o := map[string]interface{}{
+			"apiVersion": apiVersion,
+			"kind":       kind,
+			"metadata":   map[string]interface{}{"name": "app", "generation": generation},
+		}
+		if spec != nil {
+			o["spec"] = spec
+		}
+		if status != nil {
+			o["status"] = status
+		}
+		return o
+	}
+	condition := func(conditionType, status, reason string) map[string]interface{} {
+		return map[string]interface{}{"type": conditionType, "status": status, "reason": reason}
+	}
+	conditions := func(c ...interface{}) map[string]interface{} {
+		return map[string]interface{}{"observedGeneration": int64(1), "conditions": c}
+	}
+	deployment := func(status map[string]interface{}) map[string]interface{} {
+		status["observedGeneration"] = int64(2)
+		return object("apps/v1", "Deployment", 2, map[string]interface{}{"replicas": int64(3)}, status)
+	}
+
+	tests := map[string]struct {
+		object      map[string]interface{}
+		waitForJobs bool
+		status      objectStatus
+		message     string
+	}{
+		"config map": {
+			object: object("v1", "ConfigMap", 0, nil, nil),
+			status: statusCurrent,
+		},
+		"terminating": {
+			object: map[string]interface{}{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]interface{}{"name": "app", "deletionTimestamp": "2024-01-01T00:00:00Z"},
+			},
+			status:  statusTerminating,
+			message: "the object is being deleted",
+		},
+		"generation not observed": {
+			object:  object("example.com/v1", "Widget", 2, nil, conditions()),
+			status:  statusInProgress,
+			message: "generation 2 is not observed yet",
+		},
+		"deployment available": {
+			object: deployment(map[string]interface{}{
+				"replicas":          int64(3),
+				"updatedReplicas":   int64(3),
+				"readyReplicas":     int64(3),
+				"availableReplicas": int64(3),
+				"conditions": []interface{}{
+					condition("Progressing", "True", "NewReplicaSetAvailable"),
+					condition("Available", "True", "MinimumReplicasAvailable"),
+				},
+			}),
+			status:  statusCurrent,
+			message: "ready replicas: 3/3",
+		},
+		"deployment rolling out": {
+			object: deployment(map[string]interface{}{
+				"replicas":          int64(4),
+				"updatedReplicas":   int64(3),
+				"readyReplicas":     int64(4),
+				"availableReplicas": int64(4),
+			}),
+			status:  statusInProgress,
+			message: "pending termination: 1",
+		},
+		"deployment past its progress deadline": {
+			object: deployment(map[string]interface{}{
+				"conditions": []interface{}{condition("Progressing", "False", "ProgressDeadlineExceeded")},
+			}),
+			status:  statusFailed,
+			message: "ProgressDeadlineExceeded",
+		},
+		"stateful set with a partition": {
+			object: object("apps/v1", "StatefulSet", 1, map[string]interface{}{
+				"replicas": int64(3),
+				"updateStrategy": map[string]interface{}{
+					"type":          "RollingUpdate",
+					"rollingUpdate": map[string]interface{}{"partition": int64(2)},
+				},
+			}, map[string]interface{}{
+				"observedGeneration": int64(1),
+				"readyReplicas":      int64(3),
+				"updatedReplicas":    int64(1),
+				"currentRevision":    "app-1",
+				"updateRevision":     "app-2",
+			}),
+			status:  statusCurrent,
+			message: "the partition is rolled out",
+		},
+		"stateful set rolling out": {
+			object: object("apps/v1", "StatefulSet", 1, map[string]interface{}{"replicas": int64(3)}, map[string]interface{}{
+				"observedGeneration": int64(1),
+				"readyReplicas":      int64(3),
+				"currentReplicas":    int64(2),
+				"updatedReplicas":    int64(1),
+				"currentRevision":    "app-1",
+				"updateRevision":     "app-2",
+			}),
+			status:  statusInProgress,
+			message: "updated replicas: 1/3",
+		},
+		"daemon set not scheduled": {
+			object: object("apps/v1", "DaemonSet", 1, nil, map[string]interface{}{
+				"observedGeneration":     int64(1),
+				"desiredNumberScheduled": int64(3),
+				"currentNumberScheduled": int64(2),
+			}),
+			status:  statusInProgress,
+			message: "scheduled pods: 2/3",
+		},
+		"job started": {
+			object:  object("batch/v1", "Job", 1, nil, map[string]interface{}{"startTime": "2024-01-01T00:00:00Z"}),
+			status:  statusCurrent,
+			message: "the job is started",
+		},
+		"job started with wait_for_jobs": {
+			object:      object("batch/v1", "Job", 1, nil, map[string]interface{}{"startTime": "2024-01-01T00:00:00Z", "succeeded": int64(0)}),
+			waitForJobs: true,
+			status:      statusInProgress,
+			message:     "succeeded pods: 0/1",
+		},
+		"job failed": {
+			object:  object("batch/v1", "Job", 1, nil, conditions(condition("Failed", "True", "BackoffLimitExceeded"))),
+			status:  statusFailed,
+			message: "BackoffLimitExceeded",
+		},
+		"pod crash looping": {
+			object: object("v1", "Pod", 0, nil, map[string]interface{}{
+				"phase": "Running",
+				"containerStatuses": []interface{}{
+					map[string]interface{}{
+						"name":  "app",
+						"state": map[string]interface{}{"waiting": map[string]interface{}{"reason": "CrashLoopBackOff"}},
+					},
+				},
+			}),
+			status:  statusFailed,
+			message: "container app is in CrashLoopBackOff",
+		},
+		"pod ready": {
+			object: object("v1", "Pod", 0, nil, map[string]interface{}{
+				"phase":      "Running",
+				"conditions": []interface{}{condition("Ready", "True", "")},
+			}),
+			status:  statusCurrent,
+			message: "the pod is ready",
+		},
+		"load balancer without ingress": {
+			object:  object("v1", "Service", 0, map[string]interface{}{"type": "LoadBalancer"}, map[string]interface{}{}),
+			status:  statusInProgress,
+			message: "the load balancer is not provisioned yet",
+		},
+		"persistent volume claim pending": {
+			object:  object("v1", "PersistentVolumeClaim", 0, nil, map[string]interface{}{"phase": "Pending"}),
+			status:  statusInProgress,
+			message: "the claim is not bound yet",
+		},
+		"custom resource definition established": {
+			object:  object("apiextensions.k8s.io/v1", "CustomResourceDefinition", 1, nil, conditions(condition("NamesAccepted", "True", ""), condition("Established", "True", ""))),
+			status:  statusCurrent,
+			message: "the definition is established",
+		},
+		"custom resource stalled": {
+			object:  object("example.com/v1", "Widget", 1, nil, conditions(condition("Stalled", "True", "InvalidSpec"), condition("Ready", "False", "InvalidSpec"))),
+			status:  statusFailed,
+			message: "InvalidSpec",
+		},
+		"custom resource not ready": {
+			object:  object("example.com/v1", "Widget", 1, nil, conditions(condition("Ready", "False", "Provisioning"))),
+			status:  statusInProgress,
+			message: "Provisioning",
+		},
+		"custom resource ready": {
+			object: object("example.com/v1", "Widget", 1, nil, conditions(condition("Ready", "True", "Provisioned"))),
+			status: statusCurrent,
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			status, message := computeObjectStatus(tt.object, tt.waitForJobs)
+			if status != tt.status || message != tt.message {
+				t.Errorf("expected (%s, %q), got (%s, %q)", tt.status, tt.message, status, message)
+			}
+		})
+	}
+}
+
+func TestAccResourceRelease_waitStrategyKstatus(t *testing.T) {
+	name := randName("kstatus")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigWaitStrategy(testResourceName, namespace, name, "nginx"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "status", "deployed"),
+					resource.TestCheckResourceAttr("helm_release.test", "wait_strategy", "kstatus"),
+				),
+			},
+			{
+				Config:      testAccHelmReleaseConfigWaitStrategy(testResourceName, namespace, name, "registry.invalid/nginx"),
+				ExpectError: regexp.MustCompile(fmt.Sprintf(`Deployment %s/%s-test-chart: InProgress`, namespace, name)),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigWaitStrategy(resource, ns, name, image string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name          = %q
+			namespace     = %q
+			repository    = %q
+			chart         = "test-chart"
+			version       = "1.2.3"
+			wait_strategy = "kstatus"
+			timeout       = 20
+
+			set = [
+				{
+					name  = "image.repository"
+					value = %q
+				},
+			]
+		}
+	`, resource, name, ns, testRepositoryURL, image)
+}
+
+func TestValidateWaitStrategy(t *testing.T) {
+	tests := map[string]struct {
+		strategy types.String
+		atomic   bool
+		valid    bool
+	}{
+		"helm with atomic":    {types.StringNull(), true, true},
+		"kstatus":             {types.StringValue(waitStrategyKstatus), false, true},
+		"kstatus with atomic": {types.StringValue(waitStrategyKstatus), true, false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			plan := &HelmReleaseModel{WaitStrategy: tt.strategy, Atomic: types.BoolValue(tt.atomic)}
+			if diags := validateWaitStrategy(plan); diags.HasError() == tt.valid {
+				t.Errorf("expected valid to be %t, got %v", tt.valid, diags)
+			}
+		})
+	}
+}
+
+func TestValidateWaitStrategyHooks(t *testing.T) {
+	template := func(name, annotations string) *chart.File {
+		return &chart.File{Name: name, Data: []byte("apiVersion: batch/v1\nkind: Job\nmetadata:\n  name: migrate\n  annotations:\n" + annotations)}
+	}
+	subchart := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "db"},
+		Templates: []*chart.File{
+			template("templates/migrate.yaml", "    \"helm.sh/hook\": post-upgrade\n"),
+		},
+	}
+	c := &chart.Chart{
+		Metadata: &chart.Metadata{Name: "app"},
+		Templates: []*chart.File{
+			template("templates/job.yaml", "    helm.sh/hook: post-install,post-upgrade\n    helm.sh/hook-weight: \"1\"\n"),
+			template("templates/test.yaml", "    helm.sh/hook: test\n"),
+			template("templates/pre.yaml", "    helm.sh/hook: pre-install\n    helm.sh/hook-delete-policy: before-hook-creation\n"),
+		},
+	}
+	c.SetDependencies(subchart)
+
+	expected := []string{"app/templates/job.yaml", "app/charts/db/templates/migrate.yaml"}
+	if templates := postDeployHookTemplates(c, ""); !reflect.DeepEqual(templates, expected) {
+		t.Errorf("expected %v, got %v", expected, templates)
+	}
+
+	plan := &HelmReleaseModel{
+		Wait:            types.BoolValue(true),
+		WaitStrategy:    types.StringValue(waitStrategyKstatus),
+		DisableWebhooks: types.BoolValue(false),
+	}
+	if diags := validateWaitStrategyHooks(plan, c); diags.WarningsCount() != 1 {
+		t.Errorf("expected a warning for the post-install and post-upgrade hooks, got %v", diags)
+	}
+	plan.DisableWebhooks = types.BoolValue(true)
+	if diags := validateWaitStrategyHooks(plan, c); len(diags) != 0 {
+		t.Errorf("expected no warning when hooks are disabled, got %v", diags)
+	}
+	plan.DisableWebhooks = types.BoolValue(false)
+	plan.WaitStrategy = types.StringValue(waitStrategyHelm)
+	if diags := validateWaitStrategyHooks(plan, c); len(diags) != 0 {
+		t.Errorf("expected no warning with the wait of Helm, got %v", diags)
+	}
+}
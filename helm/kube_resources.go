@@ -37,11 +37,13 @@ import (
 
 // getKubeClient returns the underlying *kube.Client from an action.Configuration.
 func getKubeClient(actionConfig *action.Configuration) (*kube.Client, error) {
-	kc, ok := actionConfig.KubeClient.(*kube.Client)
-	if !ok {
-		return nil, errors.Errorf("client is not a *kube.Client")
+	switch kc := actionConfig.KubeClient.(type) {
+	case *kube.Client:
+		return kc, nil
+	case *serverSideApplyClient:
+		return kc.Client, nil
 	}
-	return kc, nil
+	return nil, errors.Errorf("client is not a *kube.Client")
 }
 
 // regenerateGVKParser builds the parser from the raw OpenAPI schema.
@@ -174,10 +176,15 @@ func mapRuntimeObjects(ctx context.Context, kc *kube.Client, objects []runtime.O
 	return mappedObjects, diags
 }
 
+// buildReleaseResources builds the objects of the manifest of a release
+func buildReleaseResources(actionConfig *action.Configuration, r *release.Release) (kube.ResourceList, error) {
+	return actionConfig.KubeClient.Build(bytes.NewBufferString(r.Manifest), false)
+}
+
 func mapResources(ctx context.Context, actionConfig *action.Configuration, r *release.Release, f func(*resource.Info) (runtime.Object, error)) (map[string]string, diag.Diagnostics) {
 	var diags diag.Diagnostics
 
-	resources, err := actionConfig.KubeClient.Build(bytes.NewBufferString(r.Manifest), false)
+	resources, err := buildReleaseResources(actionConfig, r)
 	if err != nil {
 		diags.AddError("Build Error", err.Error())
 		return nil, diags
@@ -326,3 +333,22 @@ func getDryRunResources(ctx context.Context, r *release.Release, m *Meta) (map[s
 
 	return cleaned, diags
 }
+
+// getPodLogs returns the last tailLines lines of the logs of a pod, or the
+// whole log if tailLines is 0.
+func getPodLogs(ctx context.Context, actionConfig *action.Configuration, namespace, name string, tailLines int64) (string, error) {
+	client, err := actionConfig.KubernetesClientSet()
+	if err != nil {
+		return "", errors.Wrap(err, "unable to get kubernetes client to fetch pod logs")
+	}
+
+	opts := &corev1.PodLogOptions{}
+	if tailLines > 0 {
+		opts.TailLines = &tailLines
+	}
+	logs, err := client.CoreV1().Pods(namespace).GetLogs(name, opts).DoRaw(ctx)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to get pod logs for %s", name)
+	}
+	return string(logs), nil
+}
@@ -0,0 +1,146 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const (
+	listMergeReplace    = "replace"
+	listMergeAppend     = "append"
+	listMergeMergeByKey = "merge_by_key"
+
+	defaultListMergeKey = "name"
+)
+
+// listMergeStrategies are the valid values of the strategy of list_merge
+var listMergeStrategies = []string{listMergeReplace, listMergeAppend, listMergeMergeByKey}
+
+// ListMergeModel configures how the lists of the values are merged
+type ListMergeModel struct {
+	Key      types.String `tfsdk:"key"`
+	Strategy types.String `tfsdk:"strategy"`
+}
+
+// listMerge merges values documents, combining their lists according to a
+// strategy. Maps are always merged recursively and other values replaced.
+type listMerge struct {
+	strategy string
+	key      string
+}
+
+func newListMerge(model *ListMergeModel) listMerge {
+	lm := listMerge{strategy: listMergeReplace, key: defaultListMergeKey}
+	if model == nil {
+		return lm
+	}
+	if s := model.Strategy.ValueString(); s != "" {
+		lm.strategy = s
+	}
+	if k := model.Key.ValueString(); k != "" {
+		lm.key = k
+	}
+	return lm
+}
+
+// mergeMaps merges b over a. With the replace strategy, it is equivalent to
+// mergeMaps.
+func (lm listMerge) mergeMaps(a, b map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(a))
+	for k, v := range a {
+		out[k] = v
+	}
+	for k, v := range b {
+		if current, ok := out[k]; ok {
+			out[k] = lm.mergeValue(current, v)
+			continue
+		}
+		out[k] = v
+	}
+	return out
+}
+
+// mergeInto merges b over base, in place
+func (lm listMerge) mergeInto(base, b map[string]interface{}) {
+	for k, v := range lm.mergeMaps(base, b) {
+		base[k] = v
+	}
+}
+
+func (lm listMerge) mergeValue(a, b interface{}) interface{} {
+	switch bv := b.(type) {
+	case map[string]interface{}:
+		if av, ok := a.(map[string]interface{}); ok {
+			return lm.mergeMaps(av, bv)
+		}
+	case []interface{}:
+		if av, ok := a.([]interface{}); ok {
+			return lm.mergeLists(av, bv)
+		}
+	}
+	return b
+}
+
+func (lm listMerge) mergeLists(a, b []interface{}) []interface{} {
+	switch lm.strategy {
+	case listMergeAppend:
+		return append(append([]interface{}{}, a...), b...)
+	case listMergeMergeByKey:
+		if merged, ok := lm.mergeListsByKey(a, b); ok {
+			return merged
+		}
+	}
+	return b
+}
+
+// mergeListsByKey merges the items of b with the items of a having the same
+// key, and appends the others. Lists whose items are not all maps with the key
+// can't be merged by key.
+func (lm listMerge) mergeListsByKey(a, b []interface{}) ([]interface{}, bool) {
+	out := append([]interface{}{}, a...)
+	index := make(map[string]int, len(a))
+	for i, item := range a {
+		key, ok := lm.itemKey(item)
+		if !ok {
+			return nil, false
+		}
+		index[key] = i
+	}
+
+	for _, item := range b {
+		key, ok := lm.itemKey(item)
+		if !ok {
+			return nil, false
+		}
+		if i, ok := index[key]; ok {
+			out[i] = lm.mergeMaps(out[i].(map[string]interface{}), item.(map[string]interface{}))
+			continue
+		}
+		index[key] = len(out)
+		out = append(out, item)
+	}
+	return out, true
+}
+
+func (lm listMerge) itemKey(item interface{}) (string, bool) {
+	m, ok := item.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	v, ok := m[lm.key]
+	if !ok || v == nil {
+		return "", false
+	}
+	return fmt.Sprint(v), true
+}
+
+func listMergeEqual(a, b *ListMergeModel) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Key.Equal(b.Key) && a.Strategy.Equal(b.Strategy)
+}
@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestListMerge(t *testing.T) {
+	base := map[string]interface{}{
+		"tolerations": []interface{}{
+			map[string]interface{}{"key": "dedicated", "operator": "Exists"},
+		},
+		"env": []interface{}{
+			map[string]interface{}{"name": "LOG_LEVEL", "value": "info"},
+			map[string]interface{}{"name": "PORT", "value": "8080"},
+		},
+		"args": []interface{}{"--verbose"},
+	}
+	overlay := map[string]interface{}{
+		"tolerations": []interface{}{
+			map[string]interface{}{"key": "gpu", "operator": "Exists"},
+		},
+		"env": []interface{}{
+			map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+			map[string]interface{}{"name": "REGION", "value": "eu"},
+		},
+		"args": []interface{}{"--port=8080"},
+	}
+
+	tests := map[string]struct {
+		model    *ListMergeModel
+		expected map[string]interface{}
+	}{
+		"default": {
+			model:    nil,
+			expected: overlay,
+		},
+		"replace": {
+			model:    &ListMergeModel{Strategy: types.StringValue("replace")},
+			expected: overlay,
+		},
+		"append": {
+			model: &ListMergeModel{Strategy: types.StringValue("append")},
+			expected: map[string]interface{}{
+				"tolerations": []interface{}{
+					map[string]interface{}{"key": "dedicated", "operator": "Exists"},
+					map[string]interface{}{"key": "gpu", "operator": "Exists"},
+				},
+				"env": []interface{}{
+					map[string]interface{}{"name": "LOG_LEVEL", "value": "info"},
+					map[string]interface{}{"name": "PORT", "value": "8080"},
+					map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+					map[string]interface{}{"name": "REGION", "value": "eu"},
+				},
+				"args": []interface{}{"--verbose", "--port=8080"},
+			},
+		},
+		"merge_by_key": {
+			model: &ListMergeModel{Strategy: types.StringValue("merge_by_key")},
+			expected: map[string]interface{}{
+				// Items without the key can't be merged, the list is replaced
+				"tolerations": []interface{}{
+					map[string]interface{}{"key": "gpu", "operator": "Exists"},
+				},
+				"env": []interface{}{
+					map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+					map[string]interface{}{"name": "PORT", "value": "8080"},
+					map[string]interface{}{"name": "REGION", "value": "eu"},
+				},
+				"args": []interface{}{"--port=8080"},
+			},
+		},
+		"merge_by_key with a custom key": {
+			model: &ListMergeModel{Strategy: types.StringValue("merge_by_key"), Key: types.StringValue("key")},
+			expected: map[string]interface{}{
+				"tolerations": []interface{}{
+					map[string]interface{}{"key": "dedicated", "operator": "Exists"},
+					map[string]interface{}{"key": "gpu", "operator": "Exists"},
+				},
+				"env": []interface{}{
+					map[string]interface{}{"name": "LOG_LEVEL", "value": "debug"},
+					map[string]interface{}{"name": "REGION", "value": "eu"},
+				},
+				"args": []interface{}{"--port=8080"},
+			},
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			merged := newListMerge(tt.model).mergeMaps(base, overlay)
+			if !reflect.DeepEqual(merged, tt.expected) {
+				t.Errorf("unexpected values:\n%#v\nexpected:\n%#v", merged, tt.expected)
+			}
+		})
+	}
+}
+
+func TestGetValues_listMerge(t *testing.T) {
+	ctx := context.Background()
+
+	setType := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name":  types.StringType,
+			"type":  types.StringType,
+			"value": types.StringType,
+		},
+	}
+	setListType := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name":  types.StringType,
+			"value": types.ListType{ElemType: types.StringType},
+		},
+	}
+	setList, diags := types.ListValueFrom(ctx, setListType, []set_listResourceModel{
+		{Name: types.StringValue("controller.args"), Value: types.ListValueMust(types.StringType, []attr.Value{types.StringValue("--debug")})},
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	model := &HelmReleaseModel{
+		ListMerge: &ListMergeModel{Strategy: types.StringValue("append")},
+		Values: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue("controller:\n  args: [--verbose]\ntolerations:\n- key: dedicated\n"),
+			types.StringValue("tolerations:\n- key: gpu\n"),
+		}),
+		Set:          types.ListNull(setType),
+		SetList:      setList,
+		SetSensitive: types.ListNull(setType),
+	}
+
+	values, diags := getValues(ctx, model, nil)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	expected := map[string]interface{}{
+		"controller": map[string]interface{}{
+			"args": []interface{}{"--verbose", "--debug"},
+		},
+		"tolerations": []interface{}{
+			map[string]interface{}{"key": "dedicated"},
+			map[string]interface{}{"key": "gpu"},
+		},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values:\n%#v\nexpected:\n%#v", values, expected)
+	}
+}
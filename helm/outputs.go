@@ -0,0 +1,261 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"k8s.io/client-go/util/jsonpath"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	outputSourceManifest = "manifest"
+	outputSourceLive     = "live"
+)
+
+// OutputModel selects an object of a release and the JSONPath of the value
+// to extract from it
+type OutputModel struct {
+	JSONPath  types.String `tfsdk:"json_path"`
+	Kind      types.String `tfsdk:"kind"`
+	Name      types.String `tfsdk:"name"`
+	Namespace types.String `tfsdk:"namespace"`
+	Source    types.String `tfsdk:"source"`
+}
+
+// parseOutputJSONPath parses a JSONPath expression. As with kubectl, the
+// braces around the expression are optional.
+func parseOutputJSONPath(expression string) (*jsonpath.JSONPath, error) {
+	if !strings.Contains(expression, "{") {
+		expression = fmt.Sprintf("{%s}", expression)
+	}
+	j := jsonpath.New("output")
+	if err := j.Parse(expression); err != nil {
+		return nil, err
+	}
+	return j, nil
+}
+
+// validateOutputs checks that the JSONPath expressions of the outputs are
+// valid when planning.
+func validateOutputs(ctx context.Context, outputs types.Map) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if outputs.IsNull() || outputs.IsUnknown() {
+		return diags
+	}
+
+	models := map[string]OutputModel{}
+	diags.Append(outputs.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	for name, output := range models {
+		if output.JSONPath.IsUnknown() {
+			continue
+		}
+		if _, err := parseOutputJSONPath(output.JSONPath.ValueString()); err != nil {
+			diags.AddAttributeError(
+				path.Root("outputs").AtMapKey(name).AtName("json_path"),
+				"Invalid JSONPath",
+				fmt.Sprintf("Unable to parse the JSONPath of output %q: %s", name, err),
+			)
+		}
+	}
+	return diags
+}
+
+// setReleaseOutputs evaluates the outputs of a release against its rendered
+// manifest or its live objects, and stores the results in output_values.
+// Outputs that can't be evaluated are reported in a warning and left unset.
+func setReleaseOutputs(ctx context.Context, state *HelmReleaseModel, r *release.Release, meta *Meta) diag.Diagnostics {
+	var diags diag.Diagnostics
+	state.OutputValues = types.MapNull(types.StringType)
+	if state.Outputs.IsNull() || state.Outputs.IsUnknown() {
+		return diags
+	}
+
+	models := map[string]OutputModel{}
+	diags.Append(state.Outputs.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	objects := map[string][]map[string]interface{}{}
+	for _, output := range models {
+		source := output.Source.ValueString()
+		if _, ok := objects[source]; ok {
+			continue
+		}
+		switch source {
+		case outputSourceLive:
+			live, liveDiags := liveOutputObjects(ctx, r, meta)
+			if liveDiags.HasError() {
+				diags.AddWarning(
+					"Unable to evaluate outputs",
+					fmt.Sprintf("Could not get the live objects of release %q: %s", r.Name, liveDiags.Errors()),
+				)
+			}
+			objects[source] = live
+		default:
+			manifest, err := manifestOutputObjects(r.Manifest)
+			if err != nil {
+				diags.AddWarning(
+					"Unable to evaluate outputs",
+					fmt.Sprintf("Could not parse the manifest of release %q: %s", r.Name, err),
+				)
+			}
+			objects[source] = manifest
+		}
+	}
+
+	names := make([]string, 0, len(models))
+	for name := range models {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	sensitiveValues := setSensitiveValues(ctx, state)
+	values := make(map[string]attr.Value, len(models))
+	for _, name := range names {
+		output := models[name]
+		namespace := output.Namespace.ValueString()
+		if namespace == "" {
+			namespace = r.Namespace
+		}
+		source := output.Source.ValueString()
+		object := findOutputObject(objects[source], source, output.Kind.ValueString(), namespace, output.Name.ValueString(), r.Namespace)
+		if object == nil {
+			diags.AddWarning(
+				"Unable to evaluate output",
+				fmt.Sprintf("Output %q: %s %s/%s was not found in the %s of release %q.",
+					name, output.Kind.ValueString(), namespace, output.Name.ValueString(), output.Source.ValueString(), r.Name),
+			)
+			continue
+		}
+		value, err := evaluateOutput(output.JSONPath.ValueString(), object)
+		if err != nil {
+			diags.AddWarning(
+				"Unable to evaluate output",
+				fmt.Sprintf("Output %q: %s", name, err),
+			)
+			continue
+		}
+		values[name] = types.StringValue(redactSetSensitiveValues(value, sensitiveValues))
+		tflog.Debug(ctx, fmt.Sprintf("Evaluated output %q of release %q", name, r.Name))
+	}
+
+	state.OutputValues = types.MapValueMust(types.StringType, values)
+	return diags
+}
+
+// manifestOutputObjects parses the objects of a rendered manifest. The data
+// of Secrets is hashed, as in the manifest attribute.
+func manifestOutputObjects(manifest string) ([]map[string]interface{}, error) {
+	var objects []map[string]interface{}
+	for _, document := range releaseutil.SplitManifests(manifest) {
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return nil, err
+		}
+		if object == nil {
+			continue
+		}
+		if object["kind"] == "Secret" {
+			for _, field := range []string{"data", "stringData"} {
+				data, ok := object[field].(map[string]interface{})
+				if !ok {
+					continue
+				}
+				for k, v := range data {
+					data[k] = hashSensitiveValue(fmt.Sprint(v))
+				}
+			}
+		}
+		objects = append(objects, object)
+	}
+	return objects, nil
+}
+
+// liveOutputObjects returns the live objects of a release, as gathered for
+// the resources attribute
+func liveOutputObjects(ctx context.Context, r *release.Release, meta *Meta) ([]map[string]interface{}, diag.Diagnostics) {
+	resources, diags := getLiveResources(ctx, r, meta)
+	if diags.HasError() {
+		return nil, diags
+	}
+	objects := make([]map[string]interface{}, 0, len(resources))
+	for _, resource := range resources {
+		var object map[string]interface{}
+		if err := json.Unmarshal([]byte(resource), &object); err != nil {
+			diags.AddError("Error unmarshaling live object", err.Error())
+			return nil, diags
+		}
+		objects = append(objects, object)
+	}
+	return objects, diags
+}
+
+// findOutputObject returns the object of source with the given kind,
+// namespace and name. Objects without a namespace in the manifest are in the
+// namespace of the release, which is the one the namespace is defaulted to.
+// As live objects of namespaced kinds always have a namespace, live objects
+// without one are cluster-scoped and match any namespace.
+func findOutputObject(objects []map[string]interface{}, source, kind, namespace, name, releaseNamespace string) map[string]interface{} {
+	for _, object := range objects {
+		if !strings.EqualFold(fmt.Sprint(object["kind"]), kind) {
+			continue
+		}
+		metadata, _ := object["metadata"].(map[string]interface{})
+		if metadata == nil || metadata["name"] != name {
+			continue
+		}
+		objectNamespace, _ := metadata["namespace"].(string)
+		if objectNamespace == "" && (namespace == releaseNamespace || source == outputSourceLive) {
+			return object
+		}
+		if objectNamespace != namespace {
+			continue
+		}
+		return object
+	}
+	return nil
+}
+
+// evaluateOutput evaluates a JSONPath against an object. The result is
+// formatted as with kubectl: scalars as text, objects and lists as JSON and
+// multiple results separated by spaces.
+func evaluateOutput(expression string, object map[string]interface{}) (string, error) {
+	j, err := parseOutputJSONPath(expression)
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := j.Execute(&buf, object); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+func outputAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"json_path": types.StringType,
+		"kind":      types.StringType,
+		"name":      types.StringType,
+		"namespace": types.StringType,
+		"source":    types.StringType,
+	}
+}
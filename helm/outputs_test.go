@@ -0,0 +1,253 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const testOutputsManifest = `---
+# Source: app/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: app
+spec:
+  type: ClusterIP
+  ports:
+    - name: http
+      port: 80
+    - name: metrics
+      port: 9090
+---
+# Source: app/templates/secret.yaml
+apiVersion: v1
+kind: Secret
+metadata:
+  name: app
+  namespace: apps
+stringData:
+  password: hunter2
+---
+# Source: app/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: app
+spec:
+  rules:
+    - host: app.example.com
+`
+
+func outputsValue(t *testing.T, outputs map[string]OutputModel) types.Map {
+	t.Helper()
+	value, diags := types.MapValueFrom(context.Background(), types.ObjectType{AttrTypes: outputAttrTypes()}, outputs)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	return value
+}
+
+func TestFindOutputObject(t *testing.T) {
+	object := func(kind, namespace string) map[string]interface{} {
+		metadata := map[string]interface{}{"name": "app"}
+		if namespace != "" {
+			metadata["namespace"] = namespace
+		}
+		return map[string]interface{}{"kind": kind, "metadata": metadata}
+	}
+	objects := []map[string]interface{}{
+		object("Service", ""),
+		object("Secret", "other"),
+		object("ClusterRole", ""),
+	}
+
+	tests := map[string]struct {
+		source    string
+		kind      string
+		namespace string
+		found     bool
+	}{
+		"release namespace":          {outputSourceManifest, "Service", "default", true},
+		"other namespace":            {outputSourceManifest, "Service", "other", false},
+		"explicit namespace":         {outputSourceManifest, "Secret", "other", true},
+		"explicit namespace missing": {outputSourceManifest, "Secret", "default", false},
+		"live cluster-scoped":        {outputSourceLive, "ClusterRole", "other", true},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			found := findOutputObject(objects, tt.source, tt.kind, tt.namespace, "app", "default") != nil
+			if found != tt.found {
+				t.Errorf("expected found to be %t, got %t", tt.found, found)
+			}
+		})
+	}
+}
+
+func TestSetReleaseOutputs(t *testing.T) {
+	ctx := context.Background()
+
+	output := func(kind, name, namespace, jsonPath string) OutputModel {
+		o := OutputModel{
+			Kind:      types.StringValue(kind),
+			Name:      types.StringValue(name),
+			Namespace: types.StringNull(),
+			JSONPath:  types.StringValue(jsonPath),
+			Source:    types.StringValue(outputSourceManifest),
+		}
+		if namespace != "" {
+			o.Namespace = types.StringValue(namespace)
+		}
+		return o
+	}
+	state := &HelmReleaseModel{
+		Outputs: outputsValue(t, map[string]OutputModel{
+			"service_name":  output("Service", "app", "", "{.metadata.name}"),
+			"http_port":     output("Service", "app", "default", `{.spec.ports[?(@.name=="http")].port}`),
+			"ports":         output("service", "app", "", ".spec.ports[*].port"),
+			"ingress_rules": output("Ingress", "app", "", "{.spec.rules}"),
+			"secret":        output("Secret", "app", "apps", "{.stringData.password}"),
+			"missing_field": output("Service", "app", "", "{.spec.clusterIP}"),
+			"missing":       output("Deployment", "app", "", "{.metadata.name}"),
+		}),
+		SetSensitive: types.ListNull(types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "type": types.StringType, "value": types.StringType}}),
+	}
+	r := &release.Release{Name: "app", Namespace: "default", Manifest: testOutputsManifest}
+
+	diags := setReleaseOutputs(ctx, state, r, nil)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	if diags.WarningsCount() != 2 {
+		t.Errorf("expected warnings for the missing object and field, got %v", diags)
+	}
+
+	expected := map[string]string{
+		"service_name":  "app",
+		"http_port":     "80",
+		"ports":         "80 9090",
+		"ingress_rules": `[{"host":"app.example.com"}]`,
+		"secret":        hashSensitiveValue("hunter2"),
+	}
+	elements := state.OutputValues.Elements()
+	if len(elements) != len(expected) {
+		t.Errorf("expected %d outputs, got %v", len(expected), elements)
+	}
+	for name, value := range expected {
+		if v, ok := elements[name]; !ok || !v.Equal(types.StringValue(value)) {
+			t.Errorf("expected output %q to be %q, got %v", name, value, v)
+		}
+	}
+
+	// Outputs in another namespace are not found, including objects in the
+	// namespace of the release
+	state.Outputs = outputsValue(t, map[string]OutputModel{
+		"secret":  output("Secret", "app", "default", "{.metadata.name}"),
+		"service": output("Service", "app", "other", "{.metadata.name}"),
+	})
+	diags = setReleaseOutputs(ctx, state, r, nil)
+	if diags.WarningsCount() != 2 || len(state.OutputValues.Elements()) != 0 {
+		t.Errorf("expected the secret and the service not to be found, got %v and %v", state.OutputValues, diags)
+	}
+
+	state.Outputs = types.MapNull(types.ObjectType{AttrTypes: outputAttrTypes()})
+	diags = setReleaseOutputs(ctx, state, r, nil)
+	if diags.HasError() || !state.OutputValues.IsNull() {
+		t.Errorf("expected output_values to be null without outputs, got %v", state.OutputValues)
+	}
+}
+
+func TestValidateOutputs(t *testing.T) {
+	outputs := outputsValue(t, map[string]OutputModel{
+		"valid": {
+			Kind:      types.StringValue("Service"),
+			Name:      types.StringValue("app"),
+			Namespace: types.StringNull(),
+			JSONPath:  types.StringValue(".spec.ports[0].port"),
+			Source:    types.StringValue(outputSourceManifest),
+		},
+		"invalid": {
+			Kind:      types.StringValue("Service"),
+			Name:      types.StringValue("app"),
+			Namespace: types.StringNull(),
+			JSONPath:  types.StringValue("{.spec.ports[0}"),
+			Source:    types.StringValue(outputSourceLive),
+		},
+	})
+
+	diags := validateOutputs(context.Background(), outputs)
+	if diags.ErrorsCount() != 1 {
+		t.Fatalf("expected a single error, got %v", diags)
+	}
+	withPath, ok := diags[0].(diag.DiagnosticWithPath)
+	if !ok || !withPath.Path().Equal(path.Root("outputs").AtMapKey("invalid").AtName("json_path")) {
+		t.Errorf("expected the error to be attributed to outputs[\"invalid\"].json_path, got %v", diags[0])
+	}
+}
+
+func TestAccResourceRelease_outputs(t *testing.T) {
+	name := randName("outputs")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigOutputs(testResourceName, namespace, name, "{.spec.ports[0].port}"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "output_values.service_name", fmt.Sprintf("%s-test-chart", name)),
+					resource.TestCheckResourceAttr("helm_release.test", "output_values.service_port", "80"),
+					resource.TestMatchResourceAttr("helm_release.test", "output_values.cluster_ip", regexp.MustCompile(`^\d+\.\d+\.\d+\.\d+$`)),
+				),
+			},
+			{
+				Config:      testAccHelmReleaseConfigOutputs(testResourceName, namespace, name, "{.spec.ports[0}"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Unable to parse the JSONPath of output "service_port"`),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigOutputs(resource, ns, name, portPath string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+
+			outputs = {
+				service_name = {
+					kind      = "Service"
+					name      = "%[2]s-test-chart"
+					json_path = "{.metadata.name}"
+				}
+				service_port = {
+					kind      = "Service"
+					name      = "%[2]s-test-chart"
+					json_path = %[5]q
+				}
+				cluster_ip = {
+					kind      = "Service"
+					name      = "%[2]s-test-chart"
+					json_path = "{.spec.clusterIP}"
+					source    = "live"
+				}
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, portPath)
+}
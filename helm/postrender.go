@@ -0,0 +1,393 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/postrender"
+	"sigs.k8s.io/kustomize/api/krusty"
+	kustomizetypes "sigs.k8s.io/kustomize/api/types"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"sigs.k8s.io/kustomize/kyaml/resid"
+	"sigs.k8s.io/yaml"
+)
+
+// kustomizeManifestFile is the name under which the rendered manifests are
+// made available to the kustomization of the built-in post-renderer.
+const kustomizeManifestFile = "helm-output.yaml"
+
+// Descriptions of the attributes of the built-in kustomize post-renderer,
+// shared by the schemas of helm_release and of the helm_template data source
+// and ephemeral resource.
+const (
+	kustomizeDescription                  = "Built-in post-renderer applying a kustomization to the rendered manifests, without an external kustomize binary"
+	kustomizeCommonAnnotationsDescription = "Annotations to add to all the rendered objects"
+	kustomizeCommonLabelsDescription      = "Labels to add to all the rendered objects and their pod templates. Selectors are left unchanged"
+	kustomizeImagesDescription            = "Image overrides"
+	kustomizeImageDigestDescription       = "Digest replacing the image tag"
+	kustomizeImageNameDescription         = "Name of the image to override, without tag"
+	kustomizeImageNewNameDescription      = "Name replacing the image name"
+	kustomizeImageNewTagDescription       = "Tag replacing the image tag"
+	kustomizeKustomizationDescription     = "Inline content of a kustomization.yaml. The rendered manifests are added to its resources"
+	kustomizePatchesDescription           = "Strategic merge or JSON6902 patches to apply to the rendered objects"
+	kustomizePatchDescription             = "Content of the patch, in YAML or JSON"
+	kustomizePatchTargetDescription       = "Objects the patch applies to. Required for JSON6902 patches"
+)
+
+type KustomizeModel struct {
+	CommonAnnotations types.Map    `tfsdk:"common_annotations"`
+	CommonLabels      types.Map    `tfsdk:"common_labels"`
+	Images            types.List   `tfsdk:"images"`
+	Kustomization     types.String `tfsdk:"kustomization"`
+	Patches           types.List   `tfsdk:"patches"`
+}
+
+type KustomizeImageModel struct {
+	Digest  types.String `tfsdk:"digest"`
+	Name    types.String `tfsdk:"name"`
+	NewName types.String `tfsdk:"new_name"`
+	NewTag  types.String `tfsdk:"new_tag"`
+}
+
+type KustomizePatchModel struct {
+	Patch  types.String          `tfsdk:"patch"`
+	Target *KustomizeTargetModel `tfsdk:"target"`
+}
+
+type KustomizeTargetModel struct {
+	AnnotationSelector types.String `tfsdk:"annotation_selector"`
+	Group              types.String `tfsdk:"group"`
+	Kind               types.String `tfsdk:"kind"`
+	LabelSelector      types.String `tfsdk:"label_selector"`
+	Name               types.String `tfsdk:"name"`
+	Namespace          types.String `tfsdk:"namespace"`
+	Version            types.String `tfsdk:"version"`
+}
+
+// newPostRenderer returns the post-renderer configured by a postrender block,
+// or nil if there is none. Steps are chained in order.
+func newPostRenderer(ctx context.Context, model *PostRenderModel) (postrender.PostRenderer, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	if model == nil {
+		return nil, diags
+	}
+
+	if model.Steps.IsNull() || model.Steps.IsUnknown() {
+		return newPostRenderStep(ctx, PostRenderStepModel{
+			Args:         model.Args,
+			BinaryPath:   model.BinaryPath,
+			Env:          model.Env,
+			EnvSensitive: model.EnvSensitive,
+			Kustomize:    model.Kustomize,
+			Timeout:      model.Timeout,
+			WorkingDir:   model.WorkingDir,
+		})
+	}
+
+	var steps []PostRenderStepModel
+	diags.Append(model.Steps.ElementsAs(ctx, &steps, false)...)
+	if diags.HasError() {
+		return nil, diags
+	}
+	chain := chainPostRenderer{}
+	for i, step := range steps {
+		tflog.Debug(ctx, fmt.Sprintf("Configuring post-render step %d", i))
+		pr, stepDiags := newPostRenderStep(ctx, step)
+		diags.Append(stepDiags...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		if pr != nil {
+			chain = append(chain, pr)
+		}
+	}
+	if len(chain) == 0 {
+		return nil, diags
+	}
+	return chain, diags
+}
+
+// newPostRenderStep returns the exec or built-in post-renderer of a single
+// post-render step, or nil if it is empty.
+func newPostRenderStep(ctx context.Context, step PostRenderStepModel) (postrender.PostRenderer, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if step.Kustomize != nil {
+		tflog.Debug(ctx, "Using the built-in kustomize post-renderer")
+		pr, kustomizeDiags := newKustomizePostRenderer(ctx, step.Kustomize)
+		diags.Append(kustomizeDiags...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		return pr, diags
+	}
+
+	binaryPath := step.BinaryPath.ValueString()
+	if binaryPath == "" {
+		return nil, diags
+	}
+	var args []string
+	diags.Append(step.Args.ElementsAs(ctx, &args, false)...)
+	env := map[string]string{}
+	if !step.Env.IsNull() && !step.Env.IsUnknown() {
+		diags.Append(step.Env.ElementsAs(ctx, &env, false)...)
+	}
+	sensitiveEnv := map[string]string{}
+	if !step.EnvSensitive.IsNull() && !step.EnvSensitive.IsUnknown() {
+		diags.Append(step.EnvSensitive.ElementsAs(ctx, &sensitiveEnv, false)...)
+	}
+	if diags.HasError() {
+		return nil, diags
+	}
+
+	pr := &execPostRenderer{
+		args:    args,
+		dir:     step.WorkingDir.ValueString(),
+		timeout: time.Duration(step.Timeout.ValueInt64()) * time.Second,
+	}
+	envNames := make([]string, 0, len(env)+len(sensitiveEnv))
+	for name, value := range env {
+		pr.env = append(pr.env, name+"="+value)
+		envNames = append(envNames, name)
+	}
+	for name, value := range sensitiveEnv {
+		pr.env = append(pr.env, name+"="+value)
+		envNames = append(envNames, name)
+		if value != "" {
+			pr.sensitiveValues = append(pr.sensitiveValues, value)
+		}
+	}
+	// Only the names of the environment variables are logged, as some of
+	// their values are sensitive
+	sort.Strings(envNames)
+	tflog.Debug(ctx, fmt.Sprintf("Binary path: %s, Args: %v, Env: %v, Working dir: %q", binaryPath, args, envNames, pr.dir))
+
+	fullPath, err := postRenderBinaryPath(binaryPath, pr.dir)
+	if err != nil {
+		diags.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
+		return nil, diags
+	}
+	pr.binaryPath = fullPath
+	return pr, diags
+}
+
+// postRenderBinaryPath returns the full path of a post-renderer command. As
+// for helm, a path without separators is looked up in $PATH, otherwise a
+// relative path is resolved from the working directory of the command.
+func postRenderBinaryPath(binaryPath, dir string) (string, error) {
+	if dir != "" && strings.ContainsRune(binaryPath, filepath.Separator) && !filepath.IsAbs(binaryPath) {
+		binaryPath = filepath.Join(dir, binaryPath)
+	}
+	checkedPath, err := exec.LookPath(binaryPath)
+	if err != nil {
+		return "", errors.Wrapf(err, "unable to find binary at %s", binaryPath)
+	}
+	return filepath.Abs(checkedPath)
+}
+
+// execPostRenderer is the post-renderer running an external command. Unlike
+// the helm one, it supports an extra environment, a working directory and a
+// timeout.
+type execPostRenderer struct {
+	binaryPath      string
+	args            []string
+	env             []string
+	sensitiveValues []string
+	dir             string
+	timeout         time.Duration
+}
+
+// Run implements postrender.PostRenderer
+func (p *execPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	ctx := context.Background()
+	if p.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, p.timeout)
+		defer cancel()
+	}
+
+	cmd := exec.CommandContext(ctx, p.binaryPath, p.args...)
+	cmd.Dir = p.dir
+	if len(p.env) > 0 {
+		cmd.Env = append(os.Environ(), p.env...)
+	}
+	postRendered := &bytes.Buffer{}
+	stderr := &bytes.Buffer{}
+	cmd.Stdin = renderedManifests
+	cmd.Stdout = postRendered
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		return nil, errors.Errorf("command %s timed out after %s. error output:\n%s", p.binaryPath, p.timeout, p.redact(stderr.String()))
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "error while running command %s. error output:\n%s", p.binaryPath, p.redact(stderr.String()))
+	}
+	return postRendered, nil
+}
+
+// redact replaces the values of the sensitive environment variables in the
+// output of the command.
+func (p *execPostRenderer) redact(output string) string {
+	for _, value := range p.sensitiveValues {
+		output = strings.ReplaceAll(output, value, sensitiveContentValue)
+	}
+	return output
+}
+
+// chainPostRenderer runs post-renderers in order, feeding the output of each
+// one into the next.
+type chainPostRenderer []postrender.PostRenderer
+
+// Run implements postrender.PostRenderer
+func (c chainPostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	out := renderedManifests
+	for i, pr := range c {
+		var err error
+		out, err = pr.Run(out)
+		if err != nil {
+			return nil, errors.Wrapf(err, "error while running post-render step %d", i)
+		}
+	}
+	return out, nil
+}
+
+// kustomizePostRenderer is an in-process post-renderer applying a
+// kustomization to the rendered manifests.
+type kustomizePostRenderer struct {
+	kustomization kustomizetypes.Kustomization
+}
+
+// newKustomizePostRenderer builds the kustomization described by the
+// kustomize block of a postrender block.
+func newKustomizePostRenderer(ctx context.Context, model *KustomizeModel) (*kustomizePostRenderer, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	k := kustomizetypes.Kustomization{}
+
+	if content := model.Kustomization.ValueString(); content != "" {
+		if err := yaml.UnmarshalStrict([]byte(content), &k); err != nil {
+			diags.AddError("Invalid kustomization", fmt.Sprintf("Could not parse the kustomization of the post-renderer: %s", err))
+			return nil, diags
+		}
+	}
+	k.Resources = append(k.Resources, kustomizeManifestFile)
+
+	if !model.Patches.IsNull() {
+		var patches []KustomizePatchModel
+		diags.Append(model.Patches.ElementsAs(ctx, &patches, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		for _, p := range patches {
+			patch := kustomizetypes.Patch{Patch: p.Patch.ValueString()}
+			if t := p.Target; t != nil {
+				patch.Target = &kustomizetypes.Selector{
+					ResId: resid.ResId{
+						Gvk: resid.Gvk{
+							Group:   t.Group.ValueString(),
+							Version: t.Version.ValueString(),
+							Kind:    t.Kind.ValueString(),
+						},
+						Name:      t.Name.ValueString(),
+						Namespace: t.Namespace.ValueString(),
+					},
+					AnnotationSelector: t.AnnotationSelector.ValueString(),
+					LabelSelector:      t.LabelSelector.ValueString(),
+				}
+			}
+			k.Patches = append(k.Patches, patch)
+		}
+	}
+
+	if !model.CommonLabels.IsNull() {
+		labels := map[string]string{}
+		diags.Append(model.CommonLabels.ElementsAs(ctx, &labels, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		// Selectors are left alone as they are immutable on most workloads
+		k.Labels = append(k.Labels, kustomizetypes.Label{
+			Pairs:            labels,
+			IncludeTemplates: true,
+		})
+	}
+
+	if !model.CommonAnnotations.IsNull() {
+		annotations := map[string]string{}
+		diags.Append(model.CommonAnnotations.ElementsAs(ctx, &annotations, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		if k.CommonAnnotations == nil {
+			k.CommonAnnotations = map[string]string{}
+		}
+		for key, value := range annotations {
+			k.CommonAnnotations[key] = value
+		}
+	}
+
+	if !model.Images.IsNull() {
+		var images []KustomizeImageModel
+		diags.Append(model.Images.ElementsAs(ctx, &images, false)...)
+		if diags.HasError() {
+			return nil, diags
+		}
+		for _, i := range images {
+			k.Images = append(k.Images, kustomizetypes.Image{
+				Name:    i.Name.ValueString(),
+				NewName: i.NewName.ValueString(),
+				NewTag:  i.NewTag.ValueString(),
+				Digest:  i.Digest.ValueString(),
+			})
+		}
+	}
+
+	k.FixKustomization()
+	return &kustomizePostRenderer{kustomization: k}, diags
+}
+
+// Run implements postrender.PostRenderer
+func (p *kustomizePostRenderer) Run(renderedManifests *bytes.Buffer) (*bytes.Buffer, error) {
+	// kustomize refuses empty resources, e.g. for charts only made of hooks
+	if len(bytes.TrimSpace(renderedManifests.Bytes())) == 0 {
+		return renderedManifests, nil
+	}
+
+	kustomization, err := yaml.Marshal(p.kustomization)
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to marshal kustomization")
+	}
+
+	fs := filesys.MakeFsInMemory()
+	if err := fs.WriteFile(kustomizeManifestFile, renderedManifests.Bytes()); err != nil {
+		return nil, err
+	}
+	if err := fs.WriteFile("kustomization.yaml", kustomization); err != nil {
+		return nil, err
+	}
+
+	resources, err := krusty.MakeKustomizer(krusty.MakeDefaultOptions()).Run(fs, ".")
+	if err != nil {
+		return nil, errors.Wrap(err, "error while running the kustomize post-renderer")
+	}
+	out, err := resources.AsYaml()
+	if err != nil {
+		return nil, errors.Wrap(err, "unable to render the output of the kustomize post-renderer")
+	}
+	return bytes.NewBuffer(out), nil
+}
@@ -0,0 +1,294 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+const postRenderTestManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: test
+  namespace: default
+spec:
+  selector:
+    matchLabels:
+      app: test
+  template:
+    metadata:
+      labels:
+        app: test
+    spec:
+      containers:
+      - name: nginx
+        image: nginx:1.19.5
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: test
+  namespace: default
+spec:
+  ports:
+  - port: 80
+`
+
+func TestKustomizePostRenderer(t *testing.T) {
+	ctx := context.Background()
+
+	patchType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"patch": types.StringType,
+		"target": types.ObjectType{AttrTypes: map[string]attr.Type{
+			"annotation_selector": types.StringType,
+			"group":               types.StringType,
+			"kind":                types.StringType,
+			"label_selector":      types.StringType,
+			"name":                types.StringType,
+			"namespace":           types.StringType,
+			"version":             types.StringType,
+		}},
+	}}
+	patches, diags := types.ListValueFrom(ctx, patchType, []KustomizePatchModel{
+		{
+			Patch: types.StringValue("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: test\nspec:\n  replicas: 3\n"),
+		},
+		{
+			Patch: types.StringValue(`[{"op": "add", "path": "/spec/type", "value": "NodePort"}]`),
+			Target: &KustomizeTargetModel{
+				AnnotationSelector: types.StringNull(),
+				Group:              types.StringNull(),
+				Kind:               types.StringValue("Service"),
+				LabelSelector:      types.StringNull(),
+				Name:               types.StringValue("test"),
+				Namespace:          types.StringNull(),
+				Version:            types.StringNull(),
+			},
+		},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	imageType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"digest":   types.StringType,
+		"name":     types.StringType,
+		"new_name": types.StringType,
+		"new_tag":  types.StringType,
+	}}
+	images, diags := types.ListValueFrom(ctx, imageType, []KustomizeImageModel{
+		{
+			Digest:  types.StringNull(),
+			Name:    types.StringValue("nginx"),
+			NewName: types.StringValue("registry.example.com/nginx"),
+			NewTag:  types.StringValue("1.25.0"),
+		},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	model := &KustomizeModel{
+		CommonAnnotations: types.MapValueMust(types.StringType, map[string]attr.Value{"team": types.StringValue("platform")}),
+		CommonLabels:      types.MapValueMust(types.StringType, map[string]attr.Value{"env": types.StringValue("test")}),
+		Images:            images,
+		Kustomization:     types.StringValue("namePrefix: prefix-\n"),
+		Patches:           patches,
+	}
+
+	pr, diags := newPostRenderer(ctx, &PostRenderModel{
+		Args:       types.ListNull(types.StringType),
+		BinaryPath: types.StringNull(),
+		Kustomize:  model,
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	out, err := pr.Run(bytes.NewBufferString(postRenderTestManifest))
+	if err != nil {
+		t.Fatalf("post-renderer failed: %v", err)
+	}
+
+	manifest := out.String()
+	for _, expected := range []string{
+		"name: prefix-test",
+		"replicas: 3",
+		"type: NodePort",
+		"image: registry.example.com/nginx:1.25.0",
+		"env: test",
+		"team: platform",
+	} {
+		if !strings.Contains(manifest, expected) {
+			t.Errorf("expected %q in the post-rendered manifest:\n%s", expected, manifest)
+		}
+	}
+	if strings.Contains(manifest, "matchLabels:\n      app: test\n      env: test") {
+		t.Errorf("expected selectors to be left unchanged:\n%s", manifest)
+	}
+}
+
+func TestKustomizePostRenderSchemas(t *testing.T) {
+	expected := kustomizePostRenderSchema().GetType()
+	for name, attribute := range map[string]interface{ GetType() attr.Type }{
+		"helm_template data source":        kustomizePostRenderDataSourceSchema(),
+		"helm_template ephemeral resource": kustomizePostRenderEphemeralSchema(),
+	} {
+		if actual := attribute.GetType(); !actual.Equal(expected) {
+			t.Errorf("expected the kustomize post-renderer of the %s to match the one of helm_release:\n%s\nexpected:\n%s", name, actual, expected)
+		}
+	}
+}
+
+func TestKustomizePostRenderer_invalidKustomization(t *testing.T) {
+	_, diags := newKustomizePostRenderer(context.Background(), &KustomizeModel{
+		CommonAnnotations: types.MapNull(types.StringType),
+		CommonLabels:      types.MapNull(types.StringType),
+		Images:            types.ListNull(types.ObjectType{}),
+		Kustomization:     types.StringValue("notAField: true\n"),
+		Patches:           types.ListNull(types.ObjectType{}),
+	})
+	if !diags.HasError() {
+		t.Fatal("expected an error for an invalid kustomization")
+	}
+}
+
+func TestChainPostRenderer(t *testing.T) {
+	ctx := context.Background()
+
+	stepType := types.ObjectType{AttrTypes: map[string]attr.Type{
+		"args":          types.ListType{ElemType: types.StringType},
+		"binary_path":   types.StringType,
+		"env":           types.MapType{ElemType: types.StringType},
+		"env_sensitive": types.MapType{ElemType: types.StringType},
+		"kustomize":     kustomizePostRenderSchema().GetType(),
+		"timeout":       types.Int64Type,
+		"working_dir":   types.StringType,
+	}}
+	// The exec step only matches the output of the kustomize step, which
+	// checks that the steps run in order
+	steps, diags := types.ListValueFrom(ctx, stepType, []PostRenderStepModel{
+		{
+			Args:         types.ListNull(types.StringType),
+			BinaryPath:   types.StringNull(),
+			Env:          types.MapNull(types.StringType),
+			EnvSensitive: types.MapNull(types.StringType),
+			Timeout:      types.Int64Null(),
+			WorkingDir:   types.StringNull(),
+			Kustomize: &KustomizeModel{
+				CommonAnnotations: types.MapNull(types.StringType),
+				CommonLabels:      types.MapNull(types.StringType),
+				Images:            types.ListNull(kustomizeImageType()),
+				Kustomization:     types.StringValue("namePrefix: first-\n"),
+				Patches:           types.ListNull(kustomizePatchType()),
+			},
+		},
+		{
+			Args:         types.ListValueMust(types.StringType, []attr.Value{types.StringValue("s/name: first-/name: second-first-/")}),
+			BinaryPath:   types.StringValue("sed"),
+			Env:          types.MapNull(types.StringType),
+			EnvSensitive: types.MapNull(types.StringType),
+			Timeout:      types.Int64Null(),
+			WorkingDir:   types.StringNull(),
+		},
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	pr, diags := newPostRenderer(ctx, &PostRenderModel{
+		Args:       types.ListNull(types.StringType),
+		BinaryPath: types.StringNull(),
+		Steps:      steps,
+	})
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if _, ok := pr.(chainPostRenderer); !ok {
+		t.Fatalf("expected a chain of post-renderers, got %T", pr)
+	}
+
+	out, err := pr.Run(bytes.NewBufferString(postRenderTestManifest))
+	if err != nil {
+		t.Fatalf("post-renderer failed: %v", err)
+	}
+	if manifest := out.String(); !strings.Contains(manifest, "name: second-first-test") {
+		t.Errorf("expected the steps to run in order:\n%s", manifest)
+	}
+}
+
+func kustomizeImageType() attr.Type {
+	return kustomizePostRenderSchema().GetType().(types.ObjectType).AttrTypes["images"].(types.ListType).ElemType
+}
+
+func kustomizePatchType() attr.Type {
+	return kustomizePostRenderSchema().GetType().(types.ObjectType).AttrTypes["patches"].(types.ListType).ElemType
+}
+
+func TestExecPostRenderer(t *testing.T) {
+	ctx := context.Background()
+
+	dir := t.TempDir()
+	script := "#!/bin/sh\nsed \"s/name: test/name: $NAME_PREFIX-test/\"\necho \"token: $TOKEN\" >&2\n[ -z \"$FAIL\" ]\n"
+	if err := os.WriteFile(filepath.Join(dir, "render.sh"), []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	newStep := func(env map[string]string) PostRenderStepModel {
+		envValue, _ := types.MapValueFrom(ctx, types.StringType, env)
+		return PostRenderStepModel{
+			Args:         types.ListNull(types.StringType),
+			BinaryPath:   types.StringValue("./render.sh"),
+			Env:          envValue,
+			EnvSensitive: types.MapValueMust(types.StringType, map[string]attr.Value{"TOKEN": types.StringValue("s3cr3t")}),
+			Timeout:      types.Int64Value(10),
+			WorkingDir:   types.StringValue(dir),
+		}
+	}
+
+	pr, diags := newPostRenderStep(ctx, newStep(map[string]string{"NAME_PREFIX": "env"}))
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	out, err := pr.Run(bytes.NewBufferString(postRenderTestManifest))
+	if err != nil {
+		t.Fatalf("post-renderer failed: %v", err)
+	}
+	if manifest := out.String(); !strings.Contains(manifest, "name: env-test") {
+		t.Errorf("expected the environment to be passed to the command:\n%s", manifest)
+	}
+
+	pr, diags = newPostRenderStep(ctx, newStep(map[string]string{"FAIL": "true"}))
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	_, err = pr.Run(bytes.NewBufferString(postRenderTestManifest))
+	if err == nil {
+		t.Fatal("expected the post-renderer to fail")
+	}
+	if strings.Contains(err.Error(), "s3cr3t") || !strings.Contains(err.Error(), "token: "+sensitiveContentValue) {
+		t.Errorf("expected sensitive values to be redacted from the error: %v", err)
+	}
+}
+
+func TestExecPostRenderer_timeout(t *testing.T) {
+	pr := &execPostRenderer{
+		binaryPath: "sleep",
+		args:       []string{"10"},
+		timeout:    100 * time.Millisecond,
+	}
+	_, err := pr.Run(bytes.NewBufferString(postRenderTestManifest))
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("expected a timeout error, got %v", err)
+	}
+}
@@ -17,6 +17,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/datasource"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/ephemeral"
 	"github.com/hashicorp/terraform-plugin-framework/path"
 	"github.com/hashicorp/terraform-plugin-framework/provider"
 	"github.com/hashicorp/terraform-plugin-framework/provider/schema"
@@ -30,7 +31,10 @@ import (
 	"helm.sh/helm/v3/pkg/storage/driver"
 )
 
-var _ provider.Provider = &HelmProvider{}
+var (
+	_ provider.Provider                       = &HelmProvider{}
+	_ provider.ProviderWithEphemeralResources = &HelmProvider{}
+)
 
 func New(version string) func() provider.Provider {
 	return func() provider.Provider {
@@ -631,6 +635,7 @@ func (p *HelmProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 	}
 	resp.DataSourceData = meta
 	resp.ResourceData = meta
+	resp.EphemeralResourceData = meta
 
 	tflog.Debug(ctx, "Configure method completed successfully")
 }
@@ -638,12 +643,23 @@ func (p *HelmProvider) Configure(ctx context.Context, req provider.ConfigureRequ
 func (p *HelmProvider) DataSources(ctx context.Context) []func() datasource.DataSource {
 	return []func() datasource.DataSource{
 		NewHelmTemplate,
+		NewHelmReleaseDataSource,
+		NewHelmReleasesDataSource,
+		NewHelmReleaseHistoryDataSource,
+		NewHelmChartDataSource,
 	}
 }
 
 func (p *HelmProvider) Resources(ctx context.Context) []func() resource.Resource {
 	return []func() resource.Resource{
 		NewHelmRelease,
+		NewHelmRepository,
+	}
+}
+
+func (p *HelmProvider) EphemeralResources(ctx context.Context) []func() ephemeral.EphemeralResource {
+	return []func() ephemeral.EphemeralResource{
+		NewHelmTemplateEphemeral,
 	}
 }
 
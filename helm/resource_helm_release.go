@@ -15,6 +15,7 @@ import (
 
 	"github.com/hashicorp/terraform-plugin-framework-timeouts/resource/timeouts"
 	"github.com/hashicorp/terraform-plugin-framework-validators/int64validator"
+	"github.com/hashicorp/terraform-plugin-framework-validators/listvalidator"
 	"github.com/hashicorp/terraform-plugin-framework-validators/stringvalidator"
 	"github.com/hashicorp/terraform-plugin-framework/attr"
 	"github.com/hashicorp/terraform-plugin-framework/diag"
@@ -31,7 +32,7 @@ import (
 	"github.com/hashicorp/terraform-plugin-framework/schema/validator"
 	"github.com/hashicorp/terraform-plugin-framework/tfsdk"
 	"github.com/hashicorp/terraform-plugin-framework/types"
-	"github.com/hashicorp/terraform-plugin-framework/types/basetypes"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-log/tflog"
 	"github.com/pkg/errors"
 	"helm.sh/helm/v3/pkg/action"
@@ -39,7 +40,6 @@ import (
 	"helm.sh/helm/v3/pkg/chart/loader"
 	"helm.sh/helm/v3/pkg/downloader"
 	"helm.sh/helm/v3/pkg/getter"
-	"helm.sh/helm/v3/pkg/postrender"
 	"helm.sh/helm/v3/pkg/registry"
 	"helm.sh/helm/v3/pkg/release"
 	"k8s.io/helm/pkg/strvals"
@@ -68,55 +68,71 @@ type HelmReleaseIdentityModel struct {
 }
 
 type HelmReleaseModel struct {
-	Atomic                   types.Bool       `tfsdk:"atomic"`
-	Chart                    types.String     `tfsdk:"chart"`
-	CleanupOnFail            types.Bool       `tfsdk:"cleanup_on_fail"`
-	CreateNamespace          types.Bool       `tfsdk:"create_namespace"`
-	DependencyUpdate         types.Bool       `tfsdk:"dependency_update"`
-	Description              types.String     `tfsdk:"description"`
-	Devel                    types.Bool       `tfsdk:"devel"`
-	DisableCrdHooks          types.Bool       `tfsdk:"disable_crd_hooks"`
-	DisableOpenapiValidation types.Bool       `tfsdk:"disable_openapi_validation"`
-	DisableWebhooks          types.Bool       `tfsdk:"disable_webhooks"`
-	ForceUpdate              types.Bool       `tfsdk:"force_update"`
-	ID                       types.String     `tfsdk:"id"`
-	Keyring                  types.String     `tfsdk:"keyring"`
-	Lint                     types.Bool       `tfsdk:"lint"`
-	Manifest                 types.String     `tfsdk:"manifest"`
-	MaxHistory               types.Int64      `tfsdk:"max_history"`
-	Metadata                 types.Object     `tfsdk:"metadata"`
-	Name                     types.String     `tfsdk:"name"`
-	Namespace                types.String     `tfsdk:"namespace"`
-	PassCredentials          types.Bool       `tfsdk:"pass_credentials"`
-	PostRender               *PostRenderModel `tfsdk:"postrender"`
-	Resources                types.Map        `tfsdk:"resources"`
-	RecreatePods             types.Bool       `tfsdk:"recreate_pods"`
-	Replace                  types.Bool       `tfsdk:"replace"`
-	RenderSubchartNotes      types.Bool       `tfsdk:"render_subchart_notes"`
-	Repository               types.String     `tfsdk:"repository"`
-	RepositoryCaFile         types.String     `tfsdk:"repository_ca_file"`
-	RepositoryCertFile       types.String     `tfsdk:"repository_cert_file"`
-	RepositoryKeyFile        types.String     `tfsdk:"repository_key_file"`
-	RepositoryPassword       types.String     `tfsdk:"repository_password"`
-	RepositoryUsername       types.String     `tfsdk:"repository_username"`
-	ResetValues              types.Bool       `tfsdk:"reset_values"`
-	ReuseValues              types.Bool       `tfsdk:"reuse_values"`
-	SetWO                    types.List       `tfsdk:"set_wo"`
-	SetWORevision            types.Int64      `tfsdk:"set_wo_revision"`
-	Set                      types.List       `tfsdk:"set"`
-	SetList                  types.List       `tfsdk:"set_list"`
-	SetSensitive             types.List       `tfsdk:"set_sensitive"`
-	SkipCrds                 types.Bool       `tfsdk:"skip_crds"`
-	Status                   types.String     `tfsdk:"status"`
-	TakeOwnership            types.Bool       `tfsdk:"take_ownership"`
-	Timeout                  types.Int64      `tfsdk:"timeout"`
-	Timeouts                 timeouts.Value   `tfsdk:"timeouts"`
-	UpgradeInstall           types.Bool       `tfsdk:"upgrade_install"`
-	Values                   types.List       `tfsdk:"values"`
-	Verify                   types.Bool       `tfsdk:"verify"`
-	Version                  types.String     `tfsdk:"version"`
-	Wait                     types.Bool       `tfsdk:"wait"`
-	WaitForJobs              types.Bool       `tfsdk:"wait_for_jobs"`
+	Atomic                   types.Bool            `tfsdk:"atomic"`
+	Chart                    types.String          `tfsdk:"chart"`
+	CleanupOnFail            types.Bool            `tfsdk:"cleanup_on_fail"`
+	CreateNamespace          types.Bool            `tfsdk:"create_namespace"`
+	DependencyUpdate         types.Bool            `tfsdk:"dependency_update"`
+	Description              types.String          `tfsdk:"description"`
+	Devel                    types.Bool            `tfsdk:"devel"`
+	DisableCrdHooks          types.Bool            `tfsdk:"disable_crd_hooks"`
+	DisableOpenapiValidation types.Bool            `tfsdk:"disable_openapi_validation"`
+	DisableWebhooks          types.Bool            `tfsdk:"disable_webhooks"`
+	Drift                    types.List            `tfsdk:"drift"`
+	DriftDetection           *DriftModel           `tfsdk:"drift_detection"`
+	EffectiveValues          types.String          `tfsdk:"effective_values"`
+	ForceUpdate              types.Bool            `tfsdk:"force_update"`
+	HealthChecks             types.List            `tfsdk:"health_checks"`
+	ID                       types.String          `tfsdk:"id"`
+	Keyring                  types.String          `tfsdk:"keyring"`
+	Lint                     types.Bool            `tfsdk:"lint"`
+	ListMerge                *ListMergeModel       `tfsdk:"list_merge"`
+	Manifest                 types.String          `tfsdk:"manifest"`
+	MaxHistory               types.Int64           `tfsdk:"max_history"`
+	Metadata                 types.Object          `tfsdk:"metadata"`
+	Name                     types.String          `tfsdk:"name"`
+	Namespace                types.String          `tfsdk:"namespace"`
+	OutputValues             types.Map             `tfsdk:"output_values"`
+	Outputs                  types.Map             `tfsdk:"outputs"`
+	PassCredentials          types.Bool            `tfsdk:"pass_credentials"`
+	PostRender               *PostRenderModel      `tfsdk:"postrender"`
+	Resources                types.Map             `tfsdk:"resources"`
+	RecreatePods             types.Bool            `tfsdk:"recreate_pods"`
+	Replace                  types.Bool            `tfsdk:"replace"`
+	RenderSubchartNotes      types.Bool            `tfsdk:"render_subchart_notes"`
+	Repository               types.String          `tfsdk:"repository"`
+	RepositoryCaFile         types.String          `tfsdk:"repository_ca_file"`
+	RepositoryCertFile       types.String          `tfsdk:"repository_cert_file"`
+	RepositoryKeyFile        types.String          `tfsdk:"repository_key_file"`
+	RepositoryPassword       types.String          `tfsdk:"repository_password"`
+	RepositoryUsername       types.String          `tfsdk:"repository_username"`
+	ResetValues              types.Bool            `tfsdk:"reset_values"`
+	RetainOnDestroy          types.List            `tfsdk:"retain_on_destroy"`
+	ReuseValues              types.Bool            `tfsdk:"reuse_values"`
+	RollbackOnFailure        *RollbackModel        `tfsdk:"rollback_on_failure"`
+	ServerSideApply          *ServerSideApplyModel `tfsdk:"server_side_apply"`
+	SetWO                    types.List            `tfsdk:"set_wo"`
+	SetWORevision            types.Int64           `tfsdk:"set_wo_revision"`
+	Set                      types.List            `tfsdk:"set"`
+	SetList                  types.List            `tfsdk:"set_list"`
+	SetSensitive             types.List            `tfsdk:"set_sensitive"`
+	SkipCrds                 types.Bool            `tfsdk:"skip_crds"`
+	Status                   types.String          `tfsdk:"status"`
+	TakeOwnership            types.Bool            `tfsdk:"take_ownership"`
+	Test                     *HookTestModel        `tfsdk:"test"`
+	Timeout                  types.Int64           `tfsdk:"timeout"`
+	Timeouts                 timeouts.Value        `tfsdk:"timeouts"`
+	Uninstall                *UninstallModel       `tfsdk:"uninstall"`
+	UpgradeInstall           types.Bool            `tfsdk:"upgrade_install"`
+	Values                   types.List            `tfsdk:"values"`
+	ValuesFiles              types.List            `tfsdk:"values_files"`
+	ValuesFilesSHA256        types.Map             `tfsdk:"values_files_sha256"`
+	ValuesObject             types.Dynamic         `tfsdk:"values_object"`
+	Verify                   types.Bool            `tfsdk:"verify"`
+	Version                  types.String          `tfsdk:"version"`
+	Wait                     types.Bool            `tfsdk:"wait"`
+	WaitForJobs              types.Bool            `tfsdk:"wait_for_jobs"`
+	WaitStrategy             types.String          `tfsdk:"wait_strategy"`
 }
 
 var defaultAttributes = map[string]interface{}{
@@ -169,8 +185,51 @@ type set_listResourceModel struct {
 }
 
 type PostRenderModel struct {
-	Args       types.List   `tfsdk:"args"`
-	BinaryPath types.String `tfsdk:"binary_path"`
+	Args         types.List      `tfsdk:"args"`
+	BinaryPath   types.String    `tfsdk:"binary_path"`
+	Env          types.Map       `tfsdk:"env"`
+	EnvSensitive types.Map       `tfsdk:"env_sensitive"`
+	Kustomize    *KustomizeModel `tfsdk:"kustomize"`
+	Steps        types.List      `tfsdk:"steps"`
+	Timeout      types.Int64     `tfsdk:"timeout"`
+	WorkingDir   types.String    `tfsdk:"working_dir"`
+}
+
+type PostRenderStepModel struct {
+	Args         types.List      `tfsdk:"args"`
+	BinaryPath   types.String    `tfsdk:"binary_path"`
+	Env          types.Map       `tfsdk:"env"`
+	EnvSensitive types.Map       `tfsdk:"env_sensitive"`
+	Kustomize    *KustomizeModel `tfsdk:"kustomize"`
+	Timeout      types.Int64     `tfsdk:"timeout"`
+	WorkingDir   types.String    `tfsdk:"working_dir"`
+}
+
+type RollbackModel struct {
+	Revision    types.Int64 `tfsdk:"revision"`
+	Timeout     types.Int64 `tfsdk:"timeout"`
+	Wait        types.Bool  `tfsdk:"wait"`
+	WaitForJobs types.Bool  `tfsdk:"wait_for_jobs"`
+}
+
+type UninstallModel struct {
+	DeletionPropagation types.String `tfsdk:"deletion_propagation"`
+	Description         types.String `tfsdk:"description"`
+	KeepHistory         types.Bool   `tfsdk:"keep_history"`
+	Skip                types.Bool   `tfsdk:"skip"`
+	Timeout             types.Int64  `tfsdk:"timeout"`
+}
+
+type HookTestModel struct {
+	Filter         types.List  `tfsdk:"filter"`
+	TaintOnFailure types.Bool  `tfsdk:"taint_on_failure"`
+	Timeout        types.Int64 `tfsdk:"timeout"`
+}
+
+// ServerSideApplyModel configures the server-side apply of the objects of a release
+type ServerSideApplyModel struct {
+	FieldManager   types.String `tfsdk:"field_manager"`
+	ForceConflicts types.Bool   `tfsdk:"force_conflicts"`
 }
 
 type suppressDescriptionPlanModifier struct{}
@@ -333,12 +392,68 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Default:     booldefault.StaticBool(defaultAttributes["disable_webhooks"].(bool)),
 				Description: "Prevent hooks from running",
 			},
+			"effective_values": schema.StringAttribute{
+				Computed:    true,
+				Description: "The values the release was rendered with as JSON: the default values of the chart and its subcharts coalesced with the values of the release. Sensitive values are cloaked. Not set when `set_wo` is used.",
+			},
 			"force_update": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
 				Default:     booldefault.StaticBool(defaultAttributes["force_update"].(bool)),
 				Description: "Force resource update through delete/recreate if needed.",
 			},
+			"health_checks": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Conditions the objects of the release must meet after it is installed or upgraded, in addition to `wait`. They are polled until they are all met, within the create or update timeout.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"api_version": schema.StringAttribute{
+							Required:    true,
+							Description: "API version of the object, e.g. `cert-manager.io/v1`.",
+						},
+						"condition": schema.StringAttribute{
+							Optional:    true,
+							Description: "Type of the status condition of the object to check, e.g. `Ready`.",
+							Validators: []validator.String{
+								stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("json_path")),
+							},
+						},
+						"json_path": schema.StringAttribute{
+							Optional:    true,
+							Description: "JSONPath evaluated against the object, e.g. `{.status.phase}`. The braces are optional.",
+							Validators: []validator.String{
+								stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("value")),
+							},
+						},
+						"kind": schema.StringAttribute{
+							Required:    true,
+							Description: "Kind of the object.",
+						},
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "Name of the object.",
+						},
+						"namespace": schema.StringAttribute{
+							Optional:    true,
+							Description: "Namespace of the object. Defaults to the namespace of the release.",
+						},
+						"status": schema.StringAttribute{
+							Optional:    true,
+							Description: "Expected status of `condition`. Defaults to `True`.",
+							Validators: []validator.String{
+								stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("condition")),
+							},
+						},
+						"value": schema.StringAttribute{
+							Optional:    true,
+							Description: "Expected result of `json_path`.",
+							Validators: []validator.String{
+								stringvalidator.AlsoRequires(path.MatchRelative().AtParent().AtName("json_path")),
+							},
+						},
+					},
+				},
+			},
 			"id": schema.StringAttribute{
 				Computed: true,
 			},
@@ -355,6 +470,23 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Default:     booldefault.StaticBool(defaultAttributes["lint"].(bool)),
 				Description: "Run helm lint when planning",
 			},
+			"list_merge": schema.SingleNestedAttribute{
+				Optional:    true,
+				Description: "How the lists of the values files, values documents, values_object and set_list are merged. By default, lists are replaced.",
+				Attributes: map[string]schema.Attribute{
+					"key": schema.StringAttribute{
+						Optional:    true,
+						Description: "Key identifying the items of the lists with the merge_by_key strategy. Defaults to `name`.",
+					},
+					"strategy": schema.StringAttribute{
+						Required:    true,
+						Description: "One of `replace`, `append` or `merge_by_key`.",
+						Validators: []validator.String{
+							stringvalidator.OneOf(listMergeStrategies...),
+						},
+					},
+				},
+			},
 			"manifest": schema.StringAttribute{
 				Description: "The rendered manifest as JSON.",
 				Computed:    true,
@@ -431,6 +563,44 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Description: "Namespace to install the release into",
 			},
 
+			"outputs": schema.MapNestedAttribute{
+				Optional:    true,
+				Description: "Values to extract from the objects of the release, by name. The results are stored in `output_values`.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"json_path": schema.StringAttribute{
+							Required:    true,
+							Description: "JSONPath of the value to extract, e.g. `{.spec.ports[0].port}`. The braces are optional.",
+						},
+						"kind": schema.StringAttribute{
+							Required:    true,
+							Description: "Kind of the object.",
+						},
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: "Name of the object.",
+						},
+						"namespace": schema.StringAttribute{
+							Optional:    true,
+							Description: "Namespace of the object. Defaults to the namespace of the release.",
+						},
+						"source": schema.StringAttribute{
+							Optional:    true,
+							Computed:    true,
+							Default:     stringdefault.StaticString(outputSourceManifest),
+							Description: "Whether the value is extracted from the rendered `manifest` or from the `live` object. Defaults to `manifest`.",
+							Validators: []validator.String{
+								stringvalidator.OneOf(outputSourceManifest, outputSourceLive),
+							},
+						},
+					},
+				},
+			},
+			"output_values": schema.MapAttribute{
+				Computed:    true,
+				Description: "The values extracted with `outputs`, by name.",
+				ElementType: types.StringType,
+			},
 			"pass_credentials": schema.BoolAttribute{
 				Optional:    true,
 				Description: "Pass credentials to all domains",
@@ -486,6 +656,26 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Description: "When upgrading, reset the values to the ones built into the chart",
 				Default:     booldefault.StaticBool(defaultAttributes["reset_values"].(bool)),
 			},
+			"retain_on_destroy": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: "Objects of the release that are kept when it is destroyed. They are annotated with the `helm.sh/resource-policy: keep` annotation of Helm before the release is uninstalled.",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"kind": schema.StringAttribute{
+							Required:    true,
+							Description: "Kind of the objects to keep",
+						},
+						"label_selector": schema.StringAttribute{
+							Optional:    true,
+							Description: "Label selector the objects to keep must match, e.g. `app.kubernetes.io/component=database`",
+						},
+						"name": schema.StringAttribute{
+							Optional:    true,
+							Description: "Glob pattern the names of the objects to keep must match, e.g. `data-*`. Defaults to all the objects of the kind",
+						},
+					},
+				},
+			},
 			"reuse_values": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -530,6 +720,20 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Description: "List of values in raw YAML format to pass to helm",
 				ElementType: types.StringType,
 			},
+			"values_files": schema.ListAttribute{
+				Optional:    true,
+				Description: "List of values files to pass to helm, merged before `values`. Accepts local paths, URLs supported by the helm getters and files of the chart.",
+				ElementType: types.StringType,
+			},
+			"values_files_sha256": schema.MapAttribute{
+				Computed:    true,
+				Description: "SHA256 checksums of the content of the values files, by name.",
+				ElementType: types.StringType,
+			},
+			"values_object": schema.DynamicAttribute{
+				Optional:    true,
+				Description: "Values to pass to helm as a native object. Merged over `values` and under the `set` attributes.",
+			},
 			"verify": schema.BoolAttribute{
 				Optional:    true,
 				Computed:    true,
@@ -553,6 +757,13 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 				Default:     booldefault.StaticBool(defaultAttributes["wait_for_jobs"].(bool)),
 				Description: "If wait is enabled, will wait until all Jobs have been completed before marking the release as successful.",
 			},
+			"wait_strategy": schema.StringAttribute{
+				Optional:    true,
+				Description: "How to wait for the resources of the release when wait is enabled. `helm` uses the wait of Helm, `kstatus` evaluates every object of the manifest with kstatus semantics. Defaults to `helm`.",
+				Validators: []validator.String{
+					stringvalidator.OneOf(waitStrategyHelm, waitStrategyKstatus),
+				},
+			},
 			"set": schema.ListNestedAttribute{
 				Description: "Custom values to be merged with the values",
 				Optional:    true,
@@ -658,8 +869,252 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 						ElementType: types.StringType,
 					},
 					"binary_path": schema.StringAttribute{
-						Required:    true,
+						Optional:    true,
 						Description: "The common binary path",
+						Validators: []validator.String{
+							stringvalidator.ExactlyOneOf(
+								path.MatchRelative().AtParent().AtName("kustomize"),
+								path.MatchRelative().AtParent().AtName("steps"),
+							),
+						},
+					},
+					"env": schema.MapAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+						Description: "Environment variables added to the environment of the post-renderer command",
+					},
+					"env_sensitive": schema.MapAttribute{
+						Optional:    true,
+						Sensitive:   true,
+						ElementType: types.StringType,
+						Description: "Sensitive environment variables added to the environment of the post-renderer command. They are redacted from logs and error messages",
+					},
+					"kustomize": kustomizePostRenderSchema(),
+					"steps": schema.ListNestedAttribute{
+						Optional:    true,
+						Description: "Post-renderers run in order, each one receiving the output of the previous one",
+						NestedObject: schema.NestedAttributeObject{
+							Attributes: map[string]schema.Attribute{
+								"args": schema.ListAttribute{
+									Optional:    true,
+									Description: "An argument to the post-renderer (can specify multiple)",
+									ElementType: types.StringType,
+								},
+								"binary_path": schema.StringAttribute{
+									Optional:    true,
+									Description: "The command binary path",
+									Validators: []validator.String{
+										stringvalidator.ExactlyOneOf(path.MatchRelative().AtParent().AtName("kustomize")),
+									},
+								},
+								"env": schema.MapAttribute{
+									Optional:    true,
+									ElementType: types.StringType,
+									Description: "Environment variables added to the environment of the post-renderer command",
+								},
+								"env_sensitive": schema.MapAttribute{
+									Optional:    true,
+									Sensitive:   true,
+									ElementType: types.StringType,
+									Description: "Sensitive environment variables added to the environment of the post-renderer command. They are redacted from logs and error messages",
+								},
+								"kustomize": kustomizePostRenderSchema(),
+								"timeout": schema.Int64Attribute{
+									Optional:    true,
+									Description: "Time in seconds after which the post-renderer command is killed",
+									Validators: []validator.Int64{
+										int64validator.AtLeast(1),
+									},
+								},
+								"working_dir": schema.StringAttribute{
+									Optional:    true,
+									Description: "Working directory of the post-renderer command. Relative binary paths are resolved from it",
+								},
+							},
+						},
+						Validators: []validator.List{
+							listvalidator.SizeAtLeast(1),
+						},
+					},
+					"timeout": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Time in seconds after which the post-renderer command is killed",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"working_dir": schema.StringAttribute{
+						Optional:    true,
+						Description: "Working directory of the post-renderer command. Relative binary paths are resolved from it",
+					},
+				},
+			},
+			"test": schema.SingleNestedAttribute{
+				Description: "If set, the chart tests are run after the release is installed or upgraded, as `helm test` does",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"filter": schema.ListAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+						Description: "Names of the tests to run. Names prefixed with `!` are skipped. Defaults to all the tests of the chart",
+					},
+					"taint_on_failure": schema.BoolAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     booldefault.StaticBool(true),
+						Description: "If set, failing tests fail the apply, which taints a newly created release. Otherwise test failures are reported as warnings. Defaults to `true`",
+					},
+					"timeout": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Time in seconds to wait for the tests to complete. Defaults to `timeout`",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+				},
+			},
+			"rollback_on_failure": schema.SingleNestedAttribute{
+				Description: "If set, a release whose upgrade fails is rolled back. Unlike atomic, this also applies to releases that were already failed before the upgrade",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"revision": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Revision to roll back to. Defaults to the most recent successfully deployed revision before the failed one",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"timeout": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Time in seconds to wait for any individual kubernetes operation during the rollback. Defaults to `timeout`",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
+					},
+					"wait": schema.BoolAttribute{
+						Optional:    true,
+						Description: "Will wait until all resources are in a ready state before marking the rollback as successful. Defaults to `wait`",
+					},
+					"wait_for_jobs": schema.BoolAttribute{
+						Optional:    true,
+						Description: "If wait is enabled, will wait until all Jobs have been completed before marking the rollback as successful. Defaults to `wait_for_jobs`",
+					},
+				},
+			},
+			"drift": schema.ListNestedAttribute{
+				Computed:    true,
+				Description: "The objects of the release whose live state differs from the last applied manifest, when drift_detection is set",
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"api_version": schema.StringAttribute{
+							Computed: true,
+						},
+						"deleted": schema.BoolAttribute{
+							Computed:    true,
+							Description: "Whether the object was deleted",
+						},
+						"fields": schema.ListNestedAttribute{
+							Computed:    true,
+							Description: "The fields whose live value differs from the manifest",
+							NestedObject: schema.NestedAttributeObject{
+								Attributes: map[string]schema.Attribute{
+									"actual": schema.StringAttribute{
+										Computed:    true,
+										Description: "The live value, JSON encoded",
+									},
+									"expected": schema.StringAttribute{
+										Computed:    true,
+										Description: "The value in the manifest, JSON encoded",
+									},
+									"path": schema.StringAttribute{
+										Computed:    true,
+										Description: "The path of the field, e.g. `spec.replicas`",
+									},
+								},
+							},
+						},
+						"kind": schema.StringAttribute{
+							Computed: true,
+						},
+						"name": schema.StringAttribute{
+							Computed: true,
+						},
+						"namespace": schema.StringAttribute{
+							Computed: true,
+						},
+					},
+				},
+			},
+			"drift_detection": schema.SingleNestedAttribute{
+				Description: "If set, the live objects of the release are compared to its last applied manifest on refresh, and the differences are reported in a warning and in drift",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"force_update": schema.BoolAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     booldefault.StaticBool(false),
+						Description: "If set, drift causes the release to be upgraded to restore its manifest. Defaults to `false`",
+					},
+					"ignore_fields": schema.ListAttribute{
+						Optional:    true,
+						ElementType: types.StringType,
+						Description: "Paths of the fields that are not drift, with their nested fields, e.g. `spec.replicas` for workloads scaled by an autoscaler",
+					},
+				},
+			},
+			"server_side_apply": schema.SingleNestedAttribute{
+				Description: "If set, the rendered objects are applied with server-side apply instead of Helm's client-side three-way merge. The release is still recorded in the Helm storage",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"field_manager": schema.StringAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     stringdefault.StaticString(defaultFieldManager),
+						Description: "Name of the field manager owning the applied fields. Defaults to `terraform-provider-helm`",
+					},
+					"force_conflicts": schema.BoolAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     booldefault.StaticBool(false),
+						Description: "If set, fields owned by other field managers are taken over instead of failing the apply with a conflict. Defaults to `false`",
+					},
+				},
+			},
+			"uninstall": schema.SingleNestedAttribute{
+				Description: "Options of the uninstallation of the release when it is destroyed",
+				Optional:    true,
+				Attributes: map[string]schema.Attribute{
+					"deletion_propagation": schema.StringAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     stringdefault.StaticString("background"),
+						Description: "How the dependents of the objects of the release are deleted: `background`, `foreground` or `orphan`. Defaults to `background`",
+						Validators: []validator.String{
+							stringvalidator.OneOf("background", "foreground", "orphan"),
+						},
+					},
+					"description": schema.StringAttribute{
+						Optional:    true,
+						Description: "Description of the uninstallation, recorded in the release history when keep_history is set",
+					},
+					"keep_history": schema.BoolAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     booldefault.StaticBool(false),
+						Description: "If set, the history of the release is kept after it is uninstalled, and the release is marked as uninstalled. Defaults to `false`",
+					},
+					"skip": schema.BoolAttribute{
+						Optional:    true,
+						Computed:    true,
+						Default:     booldefault.StaticBool(false),
+						Description: "If set, the release is not uninstalled when it is destroyed, and is only removed from the Terraform state. Defaults to `false`",
+					},
+					"timeout": schema.Int64Attribute{
+						Optional:    true,
+						Description: "Time in seconds to wait for any individual kubernetes operation during the uninstallation, and for the deletion of the objects of the release when wait is set. Defaults to `timeout`",
+						Validators: []validator.Int64{
+							int64validator.AtLeast(1),
+						},
 					},
 				},
 			},
@@ -668,6 +1123,94 @@ func (r *HelmRelease) Schema(ctx context.Context, req resource.SchemaRequest, re
 	}
 }
 
+// kustomizePostRenderSchema returns the schema of the built-in kustomize post-renderer,
+// shared by the postrender block and its steps.
+func kustomizePostRenderSchema() schema.SingleNestedAttribute {
+	return schema.SingleNestedAttribute{
+		Optional:    true,
+		Description: kustomizeDescription,
+		Attributes: map[string]schema.Attribute{
+			"common_annotations": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: kustomizeCommonAnnotationsDescription,
+			},
+			"common_labels": schema.MapAttribute{
+				Optional:    true,
+				ElementType: types.StringType,
+				Description: kustomizeCommonLabelsDescription,
+			},
+			"images": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: kustomizeImagesDescription,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"digest": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageDigestDescription,
+						},
+						"name": schema.StringAttribute{
+							Required:    true,
+							Description: kustomizeImageNameDescription,
+						},
+						"new_name": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageNewNameDescription,
+						},
+						"new_tag": schema.StringAttribute{
+							Optional:    true,
+							Description: kustomizeImageNewTagDescription,
+						},
+					},
+				},
+			},
+			"kustomization": schema.StringAttribute{
+				Optional:    true,
+				Description: kustomizeKustomizationDescription,
+			},
+			"patches": schema.ListNestedAttribute{
+				Optional:    true,
+				Description: kustomizePatchesDescription,
+				NestedObject: schema.NestedAttributeObject{
+					Attributes: map[string]schema.Attribute{
+						"patch": schema.StringAttribute{
+							Required:    true,
+							Description: kustomizePatchDescription,
+						},
+						"target": schema.SingleNestedAttribute{
+							Optional:    true,
+							Description: kustomizePatchTargetDescription,
+							Attributes: map[string]schema.Attribute{
+								"annotation_selector": schema.StringAttribute{
+									Optional: true,
+								},
+								"group": schema.StringAttribute{
+									Optional: true,
+								},
+								"kind": schema.StringAttribute{
+									Optional: true,
+								},
+								"label_selector": schema.StringAttribute{
+									Optional: true,
+								},
+								"name": schema.StringAttribute{
+									Optional: true,
+								},
+								"namespace": schema.StringAttribute{
+									Optional: true,
+								},
+								"version": schema.StringAttribute{
+									Optional: true,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
 func (r *HelmRelease) IdentitySchema(ctx context.Context, req resource.IdentitySchemaRequest, resp *resource.IdentitySchemaResponse) {
 	resp.IdentitySchema = identityschema.Schema{
 		Version: 0,
@@ -792,6 +1335,10 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 		resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err))
 		return
 	}
+	resp.Diagnostics.Append(useServerSideApply(ctx, actionConfig, plan.ServerSideApply)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, plan.Repository.ValueString(), plan.Chart.ValueString(), plan.RepositoryUsername.ValueString(), plan.RepositoryPassword.ValueString())
 	resp.Diagnostics.Append(ociDiags...)
 	if resp.Diagnostics.HasError() {
@@ -823,7 +1370,20 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 		}
 	}
 
-	values, valuesDiags := getValues(ctx, &plan)
+	valuesFiles, valuesFilesDiags := readValuesFiles(ctx, meta, c, plan.ValuesFiles)
+	resp.Diagnostics.Append(valuesFilesDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.ValuesFilesSHA256.IsUnknown() {
+		plan.ValuesFilesSHA256, valuesFilesDiags = valuesFilesSHA256(ctx, plan.ValuesFiles, valuesFiles)
+		resp.Diagnostics.Append(valuesFilesDiags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	values, valuesDiags := getValues(ctx, &plan, valuesFiles)
 	resp.Diagnostics.Append(valuesDiags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -849,8 +1409,8 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 	client.ClientOnly = false
 	client.DryRun = false
 	client.DisableHooks = plan.DisableWebhooks.ValueBool()
-	client.Wait = plan.Wait.ValueBool()
-	client.WaitForJobs = plan.WaitForJobs.ValueBool()
+	client.Wait = useHelmWait(&plan)
+	client.WaitForJobs = client.Wait && plan.WaitForJobs.ValueBool()
 	client.Devel = plan.Devel.ValueBool()
 	client.DependencyUpdate = plan.DependencyUpdate.ValueBool()
 	client.TakeOwnership = plan.TakeOwnership.ValueBool()
@@ -870,6 +1430,7 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 	var rel *release.Release
 
 	releaseName := plan.Name.ValueString()
+	sensitiveValues := failureSensitiveValues(ctx, &plan, &config)
 
 	if plan.UpgradeInstall.ValueBool() {
 		tflog.Debug(ctx, fmt.Sprintf("Checking if %q is already installed", releaseName))
@@ -895,7 +1456,8 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 		upgradeClient.ChartPathOptions = *cpo
 		upgradeClient.DryRun = false
 		upgradeClient.DisableHooks = plan.DisableWebhooks.ValueBool()
-		upgradeClient.Wait = plan.Wait.ValueBool()
+		upgradeClient.Wait = useHelmWait(&plan)
+		upgradeClient.WaitForJobs = upgradeClient.Wait && plan.WaitForJobs.ValueBool()
 		upgradeClient.Devel = plan.Devel.ValueBool()
 		upgradeClient.Timeout = time.Duration(plan.Timeout.ValueInt64()) * time.Second
 		upgradeClient.Namespace = plan.Namespace.ValueString()
@@ -905,45 +1467,22 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 		upgradeClient.DisableOpenAPIValidation = plan.DisableOpenapiValidation.ValueBool()
 		upgradeClient.Description = plan.Description.ValueString()
 
-		if plan.PostRender != nil {
-			binaryPath := plan.PostRender.BinaryPath.ValueString()
-			argsList := plan.PostRender.Args.Elements()
-			if binaryPath != "" {
-				var args []string
-				for _, arg := range argsList {
-					args = append(args, arg.(basetypes.StringValue).ValueString())
-				}
-				pr, err := postrender.NewExec(binaryPath, args...)
-				if err != nil {
-					resp.Diagnostics.AddError("Post-render Error", fmt.Sprintf("Could not create post-renderer: %s", err))
-					return
-				}
-				upgradeClient.PostRenderer = pr
-			}
+		pr, prDiags := newPostRenderer(ctx, plan.PostRender)
+		resp.Diagnostics.Append(prDiags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		upgradeClient.PostRenderer = pr
 
 		rel, err = upgradeClient.Run(releaseName, c, values)
 	} else {
 		tflog.Debug(ctx, fmt.Sprintf("Installing chart %q", releaseName))
-		if plan.PostRender != nil {
-			binaryPath := plan.PostRender.BinaryPath.ValueString()
-			argsList := plan.PostRender.Args.Elements()
-
-			if binaryPath != "" {
-				var args []string
-				for _, arg := range argsList {
-					args = append(args, arg.(basetypes.StringValue).ValueString())
-				}
-				tflog.Debug(ctx, fmt.Sprintf("Creating post-renderer with binary path: %s and args: %v", binaryPath, args))
-				pr, err := postrender.NewExec(binaryPath, args...)
-				if err != nil {
-					resp.Diagnostics.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
-					return
-				}
-
-				client.PostRenderer = pr
-			}
+		pr, prDiags := newPostRenderer(ctx, plan.PostRender)
+		resp.Diagnostics.Append(prDiags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		client.PostRenderer = pr
 		rel, err = client.Run(c, values)
 	}
 	if err != nil && rel == nil {
@@ -971,8 +1510,9 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 		// Save state to prevent orphaning the release from Terraform tracking
 		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 
+		report := releaseFailureReport(ctx, actionConfig, &plan, rel, sensitiveValues)
 		resp.Diagnostics.Append(diag.NewWarningDiagnostic("Helm release created with warnings", fmt.Sprintf("Helm release %q was created but has a failed status. Use the `helm` command to investigate the error, correct it, then run Terraform again.", client.ReleaseName)))
-		resp.Diagnostics.Append(diag.NewErrorDiagnostic("Helm release error", err.Error()))
+		resp.Diagnostics.Append(diag.NewErrorDiagnostic("Helm release error", withFailureReport(err.Error(), report)))
 
 		return
 	}
@@ -988,6 +1528,35 @@ func (r *HelmRelease) Create(ctx context.Context, req resource.CreateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	// The state is saved before the release is waited for and the health
+	// checks and the tests run, so a failure on creation leaves the release in
+	// state, tainted.
+	if useKstatusWait(&plan) {
+		waitDiags := waitForReleaseObjects(ctx, actionConfig, &plan, rel, sensitiveValues)
+		resp.Diagnostics.Append(waitDiags...)
+		if waitDiags.HasError() {
+			resp.Diagnostics.Append(failRelease(actionConfig, rel)...)
+			resp.Diagnostics.Append(setReleaseAttributes(ctx, &plan, resp.Identity, rel, meta)...)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			return
+		}
+	}
+
+	if !plan.HealthChecks.IsNull() {
+		healthDiags := runHealthChecks(ctx, actionConfig, &plan, rel, sensitiveValues)
+		resp.Diagnostics.Append(healthDiags...)
+		if healthDiags.HasError() {
+			resp.Diagnostics.Append(failRelease(actionConfig, rel)...)
+			resp.Diagnostics.Append(setReleaseAttributes(ctx, &plan, resp.Identity, rel, meta)...)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			return
+		}
+	}
+
+	if plan.Test != nil {
+		resp.Diagnostics.Append(runReleaseTests(ctx, actionConfig, &plan)...)
+	}
 }
 
 func (r *HelmRelease) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
@@ -1060,6 +1629,13 @@ func (r *HelmRelease) Read(ctx context.Context, req resource.ReadRequest, resp *
 		return
 	}
 
+	if state.DriftDetection != nil {
+		resp.Diagnostics.Append(detectDrift(ctx, c, release, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -1082,6 +1658,16 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 		return
 	}
 
+	// uninstall and retain_on_destroy are only used when the release is
+	// destroyed, changing them alone doesn't upgrade the release
+	if onlyDestroyOptionsChanged(req.Plan.Raw, req.State.Raw) {
+		tflog.Debug(ctx, fmt.Sprintf("[resourceReleaseUpdate: %s] Only uninstall or retain_on_destroy changed, updating state", state.Name.ValueString()))
+		state.Uninstall = plan.Uninstall
+		state.RetainOnDestroy = plan.RetainOnDestroy
+		resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+		return
+	}
+
 	updateTimeout, diags := plan.Timeouts.Update(ctx, 20*time.Minute)
 	resp.Diagnostics.Append(diags...)
 	if resp.Diagnostics.HasError() {
@@ -1110,6 +1696,10 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 		resp.Diagnostics.AddError("Error getting helm configuration", fmt.Sprintf("Unable to get Helm configuration for namespace %s: %s", namespace, err))
 		return
 	}
+	resp.Diagnostics.Append(useServerSideApply(ctx, actionConfig, plan.ServerSideApply)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
 	ociDiags := OCIRegistryLogin(ctx, meta, actionConfig, meta.RegistryClient, plan.Repository.ValueString(), plan.Chart.ValueString(), plan.RepositoryUsername.ValueString(), plan.RepositoryPassword.ValueString())
 	resp.Diagnostics.Append(ociDiags...)
 	if resp.Diagnostics.HasError() {
@@ -1146,8 +1736,8 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	client.Namespace = plan.Namespace.ValueString()
 	client.TakeOwnership = plan.TakeOwnership.ValueBool()
 	client.Timeout = time.Duration(plan.Timeout.ValueInt64()) * time.Second
-	client.Wait = plan.Wait.ValueBool()
-	client.WaitForJobs = plan.WaitForJobs.ValueBool()
+	client.Wait = useHelmWait(&plan)
+	client.WaitForJobs = client.Wait && plan.WaitForJobs.ValueBool()
 	client.DryRun = false
 	client.DisableHooks = plan.DisableWebhooks.ValueBool()
 	client.Atomic = plan.Atomic.ValueBool()
@@ -1162,25 +1752,27 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	client.CleanupOnFail = plan.CleanupOnFail.ValueBool()
 	client.Description = plan.Description.ValueString()
 
-	if plan.PostRender != nil {
-		binaryPath := plan.PostRender.BinaryPath.ValueString()
-		argsList := plan.PostRender.Args.Elements()
+	pr, prDiags := newPostRenderer(ctx, plan.PostRender)
+	resp.Diagnostics.Append(prDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	client.PostRenderer = pr
 
-		if binaryPath != "" {
-			var args []string
-			for _, arg := range argsList {
-				args = append(args, arg.(basetypes.StringValue).ValueString())
-			}
-			tflog.Debug(ctx, fmt.Sprintf("Binary path update method: %s, Args: %v", binaryPath, args))
-			pr, err := postrender.NewExec(binaryPath, args...)
-			if err != nil {
-				resp.Diagnostics.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
-				return
-			}
-			client.PostRenderer = pr
+	valuesFiles, valuesFilesDiags := readValuesFiles(ctx, meta, c, plan.ValuesFiles)
+	resp.Diagnostics.Append(valuesFilesDiags...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+	if plan.ValuesFilesSHA256.IsUnknown() {
+		plan.ValuesFilesSHA256, valuesFilesDiags = valuesFilesSHA256(ctx, plan.ValuesFiles, valuesFiles)
+		resp.Diagnostics.Append(valuesFilesDiags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
 	}
-	values, valuesDiags := getValues(ctx, &plan)
+
+	values, valuesDiags := getValues(ctx, &plan, valuesFiles)
 	resp.Diagnostics.Append(valuesDiags...)
 	if resp.Diagnostics.HasError() {
 		return
@@ -1197,6 +1789,7 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	}
 
 	name := plan.Name.ValueString()
+	sensitiveValues := failureSensitiveValues(ctx, &plan, &config)
 	rel, err := client.Run(name, c, values)
 
 	// Handle upgrade failure - check if release exists and save state to prevent orphaning
@@ -1210,6 +1803,12 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 		}
 		// Release exists - save state with current release info to prevent state loss
 		if existingRelease != nil {
+			report := releaseFailureReport(ctx, actionConfig, &plan, existingRelease, sensitiveValues)
+			if plan.RollbackOnFailure != nil {
+				resp.Diagnostics.AddError("Helm release upgrade failed", withFailureReport(fmt.Sprintf("Helm release %q upgrade failed: %s", name, err), report))
+				r.rollbackFailedUpgrade(ctx, actionConfig, &plan, &state, existingRelease, sensitiveValues, resp)
+				return
+			}
 			tflog.Debug(ctx, fmt.Sprintf("%s Upgrade failed but release exists with status %s, saving state", logID, existingRelease.Info.Status))
 			diags = setReleaseAttributes(ctx, &plan, resp.Identity, existingRelease, meta)
 			resp.Diagnostics.Append(diags...)
@@ -1218,7 +1817,7 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 			}
 			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 			resp.Diagnostics.Append(diag.NewWarningDiagnostic("Helm release upgrade failed", fmt.Sprintf("Helm release %q upgrade failed but release exists with status %s. Use the `helm` command to investigate the error, correct it, then run Terraform again.", name, existingRelease.Info.Status)))
-			resp.Diagnostics.Append(diag.NewErrorDiagnostic("Helm release error", err.Error()))
+			resp.Diagnostics.Append(diag.NewErrorDiagnostic("Helm release error", withFailureReport(err.Error(), report)))
 			return
 		}
 		resp.Diagnostics.AddError("Error upgrading chart", fmt.Sprintf("Upgrade failed: %s", err))
@@ -1237,6 +1836,14 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 			return
 		}
 
+		// The report is collected before a rollback replaces the failed objects
+		report := releaseFailureReport(ctx, actionConfig, &plan, rel, sensitiveValues)
+		if plan.RollbackOnFailure != nil {
+			resp.Diagnostics.AddError("Helm release upgrade failed", withFailureReport(fmt.Sprintf("Helm release %q upgrade failed: %s", name, err), report))
+			r.rollbackFailedUpgrade(ctx, actionConfig, &plan, &state, rel, sensitiveValues, resp)
+			return
+		}
+
 		tflog.Debug(ctx, fmt.Sprintf("%s Upgrade failed but release exists with status %s, saving state", logID, rel.Info.Status))
 		diags = setReleaseAttributes(ctx, &plan, resp.Identity, rel, meta)
 		resp.Diagnostics.Append(diags...)
@@ -1246,7 +1853,7 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 
 		resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
 		resp.Diagnostics.Append(diag.NewWarningDiagnostic("Helm release upgrade failed", fmt.Sprintf("Helm release %q upgrade failed but has status %s. Use the `helm` command to investigate the error, correct it, then run Terraform again.", name, rel.Info.Status)))
-		resp.Diagnostics.Append(diag.NewErrorDiagnostic("Helm release error", err.Error()))
+		resp.Diagnostics.Append(diag.NewErrorDiagnostic("Helm release error", withFailureReport(err.Error(), report)))
 		return
 	}
 
@@ -1261,6 +1868,277 @@ func (r *HelmRelease) Update(ctx context.Context, req resource.UpdateRequest, re
 	if resp.Diagnostics.HasError() {
 		return
 	}
+
+	if useKstatusWait(&plan) {
+		waitDiags := waitForReleaseObjects(ctx, actionConfig, &plan, rel, sensitiveValues)
+		resp.Diagnostics.Append(waitDiags...)
+		if waitDiags.HasError() {
+			resp.Diagnostics.Append(failRelease(actionConfig, rel)...)
+			if plan.RollbackOnFailure != nil {
+				r.rollbackFailedUpgrade(ctx, actionConfig, &plan, &state, rel, sensitiveValues, resp)
+				return
+			}
+			resp.Diagnostics.Append(setReleaseAttributes(ctx, &plan, resp.Identity, rel, meta)...)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			return
+		}
+	}
+
+	if !plan.HealthChecks.IsNull() {
+		healthDiags := runHealthChecks(ctx, actionConfig, &plan, rel, sensitiveValues)
+		resp.Diagnostics.Append(healthDiags...)
+		if healthDiags.HasError() {
+			resp.Diagnostics.Append(failRelease(actionConfig, rel)...)
+			if plan.RollbackOnFailure != nil {
+				r.rollbackFailedUpgrade(ctx, actionConfig, &plan, &state, rel, sensitiveValues, resp)
+				return
+			}
+			resp.Diagnostics.Append(setReleaseAttributes(ctx, &plan, resp.Identity, rel, meta)...)
+			resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+			return
+		}
+	}
+
+	if plan.Test != nil {
+		resp.Diagnostics.Append(runReleaseTests(ctx, actionConfig, &plan)...)
+	}
+}
+
+// onlyDestroyOptionsChanged returns whether uninstall or retain_on_destroy
+// are the only attributes changed by the plan of a release. The attributes
+// computed from the release that are unknown in the plan are kept from the
+// state, except values_files_sha256, which is unknown when the content of the
+// values files has to be read again.
+func onlyDestroyOptionsChanged(plan, state tftypes.Value) bool {
+	var planAttributes, stateAttributes map[string]tftypes.Value
+	if err := plan.As(&planAttributes); err != nil {
+		return false
+	}
+	if err := state.As(&stateAttributes); err != nil {
+		return false
+	}
+
+	changed := false
+	for name, value := range planAttributes {
+		switch name {
+		case "uninstall", "retain_on_destroy":
+			if !value.Equal(stateAttributes[name]) {
+				changed = true
+			}
+			continue
+		case "drift", "effective_values", "id", "manifest", "metadata", "output_values", "resources", "status":
+			if !value.IsKnown() {
+				continue
+			}
+		}
+		if !value.Equal(stateAttributes[name]) {
+			return false
+		}
+	}
+	return changed
+}
+
+// rollbackFailedUpgrade rolls back a release whose upgrade failed, as configured
+// by rollback_on_failure, and saves the resulting release in state. The
+// outcome of the rollback is reported, the upgrade failure is reported by the
+// caller.
+func (r *HelmRelease) rollbackFailedUpgrade(ctx context.Context, actionConfig *action.Configuration, plan, state *HelmReleaseModel, failed *release.Release, sensitiveValues map[string]string, resp *resource.UpdateResponse) {
+	name := plan.Name.ValueString()
+	logID := fmt.Sprintf("[resourceReleaseUpdate: %s]", name)
+	tflog.Debug(ctx, fmt.Sprintf("%s Upgrade failed with release status %s, rolling back", logID, failed.Info.Status))
+
+	rolledBack, diags := rollbackRelease(ctx, r.meta, actionConfig, plan, failed, sensitiveValues)
+	resp.Diagnostics.Append(diags...)
+
+	// A successful rollback restores the previously applied configuration, so
+	// keep the prior state so that the next plan retries the upgrade.
+	model, current := plan, failed
+	if rolledBack != nil {
+		model, current = state, rolledBack
+	}
+	diags = setReleaseAttributes(ctx, model, resp.Identity, current, r.meta)
+	resp.Diagnostics.Append(diags...)
+	if diags.HasError() {
+		return
+	}
+	resp.Diagnostics.Append(resp.State.Set(ctx, model)...)
+}
+
+// rollbackRelease rolls a failed release back to the revision selected by the
+// rollback_on_failure block and returns the release after the rollback. It
+// returns nil if no rollback was done. The rollback is waited for with the
+// wait strategy of the release.
+func rollbackRelease(ctx context.Context, meta *Meta, actionConfig *action.Configuration, plan *HelmReleaseModel, failed *release.Release, sensitiveValues map[string]string) (*release.Release, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	name := failed.Name
+
+	// The upgrade may fail before a new revision is recorded, e.g. when the
+	// chart does not render. The release is then left as it was.
+	if failed.Info.Status != release.StatusFailed {
+		diags.AddWarning(
+			"Helm release not rolled back",
+			fmt.Sprintf("Helm release %q was not rolled back because its current revision %d has status %s.", name, failed.Version, failed.Info.Status),
+		)
+		return nil, diags
+	}
+
+	opts := plan.RollbackOnFailure
+	revision := int(opts.Revision.ValueInt64())
+	if revision == 0 {
+		history, err := action.NewHistory(actionConfig).Run(name)
+		if err != nil {
+			diags.AddError("Error rolling back release", fmt.Sprintf("Unable to get the history of Helm release %q: %s", name, err))
+			return nil, diags
+		}
+		revision = lastDeployedRevision(history, failed.Version)
+		if revision == 0 {
+			diags.AddError(
+				"Error rolling back release",
+				fmt.Sprintf("Helm release %q has no successfully deployed revision before revision %d to roll back to.", name, failed.Version),
+			)
+			return nil, diags
+		}
+	}
+
+	client := action.NewRollback(actionConfig)
+	client.Version = revision
+	client.Timeout = time.Duration(plan.Timeout.ValueInt64()) * time.Second
+	if !opts.Timeout.IsNull() {
+		client.Timeout = time.Duration(opts.Timeout.ValueInt64()) * time.Second
+	}
+	wait := plan.Wait.ValueBool()
+	if !opts.Wait.IsNull() {
+		wait = opts.Wait.ValueBool()
+	}
+	waitForJobs := plan.WaitForJobs.ValueBool()
+	if !opts.WaitForJobs.IsNull() {
+		waitForJobs = opts.WaitForJobs.ValueBool()
+	}
+	kstatusWait := wait && plan.WaitStrategy.ValueString() == waitStrategyKstatus
+	client.Wait = wait && !kstatusWait
+	client.WaitForJobs = client.Wait && waitForJobs
+	client.DisableHooks = plan.DisableWebhooks.ValueBool()
+	client.Recreate = plan.RecreatePods.ValueBool()
+	client.Force = plan.ForceUpdate.ValueBool()
+	client.CleanupOnFail = plan.CleanupOnFail.ValueBool()
+	client.MaxHistory = int(plan.MaxHistory.ValueInt64())
+
+	tflog.Debug(ctx, fmt.Sprintf("Rolling back release %s from revision %d to revision %d", name, failed.Version, revision))
+	if err := client.Run(name); err != nil {
+		diags.AddError("Error rolling back release", fmt.Sprintf("Rollback of Helm release %q to revision %d failed: %s", name, revision, err))
+		return nil, diags
+	}
+
+	rel, err := getRelease(ctx, meta, actionConfig, name)
+	if err != nil {
+		diags.AddError("Error rolling back release", fmt.Sprintf("Unable to get Helm release %q after the rollback: %s", name, err))
+		return nil, diags
+	}
+
+	if kstatusWait {
+		waitPlan := *plan
+		waitPlan.Timeout = types.Int64Value(int64(client.Timeout / time.Second))
+		waitPlan.WaitForJobs = types.BoolValue(waitForJobs)
+		waitDiags := waitForReleaseObjects(ctx, actionConfig, &waitPlan, rel, sensitiveValues)
+		diags.Append(waitDiags...)
+		if waitDiags.HasError() {
+			diags.Append(failRelease(actionConfig, rel)...)
+		}
+	}
+
+	diags.AddWarning(
+		"Helm release rolled back",
+		fmt.Sprintf("Helm release %q was rolled back to revision %d after the upgrade failed. The release is now at revision %d with status %s.", name, revision, rel.Version, rel.Info.Status),
+	)
+	return rel, diags
+}
+
+// releaseTestLogLines is the number of log lines of each failed test pod
+// included in the diagnostic.
+const releaseTestLogLines = 100
+
+// runReleaseTests runs the test hooks of a release as configured by the test
+// block. Test failures are reported as errors if taint_on_failure is set, as
+// warnings otherwise, along with the logs of the failed test pods.
+func runReleaseTests(ctx context.Context, actionConfig *action.Configuration, plan *HelmReleaseModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	name := plan.Name.ValueString()
+	namespace := plan.Namespace.ValueString()
+
+	client := action.NewReleaseTesting(actionConfig)
+	client.Namespace = namespace
+	client.Timeout = time.Duration(plan.Timeout.ValueInt64()) * time.Second
+	if !plan.Test.Timeout.IsNull() {
+		client.Timeout = time.Duration(plan.Test.Timeout.ValueInt64()) * time.Second
+	}
+	if !plan.Test.Filter.IsNull() {
+		var filter []string
+		diags.Append(plan.Test.Filter.ElementsAs(ctx, &filter, false)...)
+		if diags.HasError() {
+			return diags
+		}
+		client.Filters = releaseTestFilters(filter)
+	}
+
+	tflog.Debug(ctx, fmt.Sprintf("Running tests of release %s", name))
+	rel, err := client.Run(name)
+	if err == nil {
+		tflog.Debug(ctx, fmt.Sprintf("Tests of release %s passed", name))
+		return diags
+	}
+
+	var detail strings.Builder
+	fmt.Fprintf(&detail, "Tests of Helm release %q failed: %s", name, err)
+	if rel != nil {
+		for _, h := range rel.Hooks {
+			if !isTestHook(h) || h.LastRun.Phase != release.HookPhaseFailed {
+				continue
+			}
+			logs, err := getPodLogs(ctx, actionConfig, namespace, h.Name, releaseTestLogLines)
+			if err != nil {
+				fmt.Fprintf(&detail, "\n\nTest %s failed, %s", h.Name, err)
+				continue
+			}
+			fmt.Fprintf(&detail, "\n\nTest %s failed, last %d lines of its logs:\n%s", h.Name, releaseTestLogLines, logs)
+		}
+	}
+
+	if plan.Test.TaintOnFailure.ValueBool() {
+		diags.AddError("Helm release tests failed", detail.String())
+	} else {
+		diags.AddWarning("Helm release tests failed", detail.String())
+	}
+	return diags
+}
+
+// releaseTestFilters converts a list of test names, where names prefixed with
+// `!` are excluded, into the filters of the ReleaseTesting action.
+func releaseTestFilters(names []string) map[string][]string {
+	filters := map[string][]string{}
+	for _, n := range names {
+		if strings.HasPrefix(n, "!") {
+			filters[action.ExcludeNameFilter] = append(filters[action.ExcludeNameFilter], strings.TrimPrefix(n, "!"))
+		} else {
+			filters[action.IncludeNameFilter] = append(filters[action.IncludeNameFilter], n)
+		}
+	}
+	return filters
+}
+
+// lastDeployedRevision returns the most recent revision older than before that
+// was successfully deployed, or 0 if there is none.
+func lastDeployedRevision(history []*release.Release, before int) int {
+	revision := 0
+	for _, r := range history {
+		if r.Version >= before || r.Version <= revision {
+			continue
+		}
+		switch r.Info.Status {
+		case release.StatusDeployed, release.StatusSuperseded:
+			revision = r.Version
+		}
+	}
+	return revision
 }
 
 func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
@@ -1302,6 +2180,11 @@ func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, re
 	name := state.Name.ValueString()
 	namespace := state.Namespace.ValueString()
 
+	if state.Uninstall != nil && state.Uninstall.Skip.ValueBool() {
+		tflog.Info(ctx, fmt.Sprintf("Skipping the uninstallation of Helm release %s, removing it from state only", name))
+		return
+	}
+
 	exists, diags := resourceReleaseExists(ctx, name, namespace, meta)
 	if !exists {
 		return
@@ -1323,11 +2206,26 @@ func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, re
 	}
 	tflog.Debug(ctx, fmt.Sprintf("Retrieved Helm configuration for namespace: %s", namespace))
 
+	if !state.RetainOnDestroy.IsNull() {
+		resp.Diagnostics.Append(retainReleaseObjects(ctx, actionConfig, &state)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
 	// Initialize uninstall action
 	uninstall := action.NewUninstall(actionConfig)
 	uninstall.Wait = state.Wait.ValueBool()
 	uninstall.DisableHooks = state.DisableWebhooks.ValueBool()
 	uninstall.Timeout = time.Duration(state.Timeout.ValueInt64()) * time.Second
+	if opts := state.Uninstall; opts != nil {
+		uninstall.KeepHistory = opts.KeepHistory.ValueBool()
+		uninstall.DeletionPropagation = opts.DeletionPropagation.ValueString()
+		uninstall.Description = opts.Description.ValueString()
+		if !opts.Timeout.IsNull() {
+			uninstall.Timeout = time.Duration(opts.Timeout.ValueInt64()) * time.Second
+		}
+	}
 
 	// Uninstall the release
 	tflog.Info(ctx, fmt.Sprintf("Uninstalling Helm release: %s", name))
@@ -1350,9 +2248,43 @@ func (r *HelmRelease) Delete(ctx context.Context, req resource.DeleteRequest, re
 }
 
 func chartPathOptions(model *HelmReleaseModel, meta *Meta, cpo *action.ChartPathOptions) (*action.ChartPathOptions, string, diag.Diagnostics) {
+	return chartLocationPathOptions(chartLocation{
+		chart:           model.Chart.ValueString(),
+		repository:      model.Repository.ValueString(),
+		version:         getVersion(model),
+		caFile:          model.RepositoryCaFile.ValueString(),
+		certFile:        model.RepositoryCertFile.ValueString(),
+		keyFile:         model.RepositoryKeyFile.ValueString(),
+		keyring:         model.Keyring.ValueString(),
+		username:        model.RepositoryUsername.ValueString(),
+		password:        model.RepositoryPassword.ValueString(),
+		verify:          model.Verify.ValueBool(),
+		passCredentials: model.PassCredentials.ValueBool(),
+	}, cpo)
+}
+
+// chartLocation holds the attributes that locate a chart, along with the
+// credentials of its repository
+type chartLocation struct {
+	chart           string
+	repository      string
+	version         string
+	caFile          string
+	certFile        string
+	keyFile         string
+	keyring         string
+	username        string
+	password        string
+	verify          bool
+	passCredentials bool
+}
+
+// chartLocationPathOptions configures cpo to locate a chart, and returns the
+// name of the chart to pass to LocateChart
+func chartLocationPathOptions(location chartLocation, cpo *action.ChartPathOptions) (*action.ChartPathOptions, string, diag.Diagnostics) {
 	var diags diag.Diagnostics
-	chartName := model.Chart.ValueString()
-	repository := model.Repository.ValueString()
+	chartName := location.chart
+	repository := location.repository
 
 	var repositoryURL string
 	if registry.IsOCI(repository) {
@@ -1373,20 +2305,18 @@ func chartPathOptions(model *HelmReleaseModel, meta *Meta, cpo *action.ChartPath
 		}
 	}
 
-	version := getVersion(model)
-
-	cpo.CaFile = model.RepositoryCaFile.ValueString()
-	cpo.CertFile = model.RepositoryCertFile.ValueString()
-	cpo.KeyFile = model.RepositoryKeyFile.ValueString()
-	cpo.Keyring = model.Keyring.ValueString()
+	cpo.CaFile = location.caFile
+	cpo.CertFile = location.certFile
+	cpo.KeyFile = location.keyFile
+	cpo.Keyring = location.keyring
 	cpo.RepoURL = repositoryURL
-	cpo.Verify = model.Verify.ValueBool()
+	cpo.Verify = location.verify
 	if !useChartVersion(chartName, cpo.RepoURL) {
-		cpo.Version = version
+		cpo.Version = location.version
 	}
-	cpo.Username = model.RepositoryUsername.ValueString()
-	cpo.Password = model.RepositoryPassword.ValueString()
-	cpo.PassCredentialsAll = model.PassCredentials.ValueBool()
+	cpo.Username = location.username
+	cpo.Password = location.password
+	cpo.PassCredentialsAll = location.passCredentials
 
 	return cpo, chartName, diags
 }
@@ -1482,9 +2412,21 @@ func getWriteOnlyValues(ctx context.Context, model *HelmReleaseModel) (map[strin
 	return base, diags
 }
 
-func getValues(ctx context.Context, model *HelmReleaseModel) (map[string]interface{}, diag.Diagnostics) {
+func getValues(ctx context.Context, model *HelmReleaseModel, valuesFiles []valuesFile) (map[string]interface{}, diag.Diagnostics) {
 	base := map[string]interface{}{}
 	var diags diag.Diagnostics
+	lm := newListMerge(model.ListMerge)
+
+	// Processing "values_files" attribute
+	for _, f := range valuesFiles {
+		currentMap := map[string]interface{}{}
+		if err := yaml.Unmarshal(f.content, &currentMap); err != nil {
+			diags.AddError("Error unmarshaling values file", fmt.Sprintf("Unable to parse values file %s: %s", f.name, err))
+			return nil, diags
+		}
+
+		base = lm.mergeMaps(base, currentMap)
+	}
 
 	// Processing "values" attribute
 	for _, raw := range model.Values.Elements() {
@@ -1509,8 +2451,16 @@ func getValues(ctx context.Context, model *HelmReleaseModel) (map[string]interfa
 			return nil, diags
 		}
 
-		base = mergeMaps(base, currentMap)
+		base = lm.mergeMaps(base, currentMap)
+	}
+
+	// Processing "values_object" attribute
+	valuesObject, valuesObjectDiags := getValuesObject(model.ValuesObject)
+	diags.Append(valuesObjectDiags...)
+	if diags.HasError() {
+		return nil, diags
 	}
+	base = lm.mergeMaps(base, valuesObject)
 
 	// Processing "set" attribute
 	if !model.Set.IsNull() {
@@ -1546,7 +2496,7 @@ func getValues(ctx context.Context, model *HelmReleaseModel) (map[string]interfa
 
 		for i, setList := range setListSlice {
 			tflog.Debug(ctx, fmt.Sprintf("Processing Set_list element at index %d: %v", i, setList))
-			setListDiags := getListValue(ctx, base, setList)
+			setListDiags := getListValue(ctx, base, setList, lm)
 			diags.Append(setListDiags...)
 			if diags.HasError() {
 				tflog.Debug(ctx, fmt.Sprintf("Error occurred while processing Set_list element at index %d", i))
@@ -1676,7 +2626,7 @@ func cloakSetValues(config map[string]interface{}, state *HelmReleaseModel) {
 	}
 }
 
-func getListValue(ctx context.Context, base map[string]interface{}, set set_listResourceModel) diag.Diagnostics {
+func getListValue(ctx context.Context, base map[string]interface{}, set set_listResourceModel, lm listMerge) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	name := set.Name.ValueString()
@@ -1701,10 +2651,18 @@ func getListValue(ctx context.Context, base map[string]interface{}, set set_list
 	// Join the list into a single string
 	listString := strings.Join(listStringArray, ",")
 
-	if err := strvals.ParseInto(fmt.Sprintf("%s={%s}", name, listString), base); err != nil {
+	// Lists are merged with the current ones unless they are replaced
+	target := base
+	if lm.strategy != listMergeReplace {
+		target = map[string]interface{}{}
+	}
+	if err := strvals.ParseInto(fmt.Sprintf("%s={%s}", name, listString), target); err != nil {
 		diags.AddError("Error parsing list value", fmt.Sprintf("Failed parsing key %q with value %s: %s", name, listString, err))
 		return diags
 	}
+	if lm.strategy != listMergeReplace {
+		lm.mergeInto(base, target)
+	}
 
 	return diags
 }
@@ -1718,6 +2676,7 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, identity
 	// Update state with attributes from the helm release
 	state.Resources = types.MapNull(types.StringType)
 	state.Manifest = types.StringNull()
+	state.Drift = noDrift(state.DriftDetection)
 	state.Name = types.StringValue(r.Name)
 	version := r.Chart.Metadata.Version
 	if !versionsEqual(version, state.Version.ValueString()) {
@@ -1729,13 +2688,16 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, identity
 
 	state.ID = types.StringValue(r.Name)
 
-	rid := HelmReleaseIdentityModel{
-		Namespace:   types.StringValue(r.Namespace),
-		ReleaseName: types.StringValue(r.Name),
-	}
-	diags = identity.Set(ctx, rid)
-	if diags.HasError() {
-		return diags
+	// identity is nil when called from the helm_release data source
+	if identity != nil {
+		rid := HelmReleaseIdentityModel{
+			Namespace:   types.StringValue(r.Namespace),
+			ReleaseName: types.StringValue(r.Name),
+		}
+		diags = identity.Set(ctx, rid)
+		if diags.HasError() {
+			return diags
+		}
 	}
 
 	// Cloak sensitive values in the release config
@@ -1794,18 +2756,25 @@ func setReleaseAttributes(ctx context.Context, state *HelmReleaseModel, identity
 		valuesstr = types.StringValue(values)
 	}
 
-	// Create metadata as a slice of maps
-	metadata := map[string]attr.Value{
-		"name":           types.StringValue(r.Name),
-		"revision":       types.Int64Value(int64(r.Version)),
-		"namespace":      types.StringValue(r.Namespace),
-		"chart":          types.StringValue(r.Chart.Metadata.Name),
-		"version":        types.StringValue(r.Chart.Metadata.Version),
-		"app_version":    types.StringValue(r.Chart.Metadata.AppVersion),
-		"values":         valuesstr,
-		"first_deployed": types.Int64Value(r.Info.FirstDeployed.Unix()),
-		"last_deployed":  types.Int64Value(r.Info.LastDeployed.Unix()),
-		"notes":          types.StringValue(r.Info.Notes),
+	diags.Append(setReleaseOutputs(ctx, state, r, meta)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	metadata := releaseMetadataAttributes(r, valuesstr)
+
+	// NOTE The write-only values are part of the effective values and can't be
+	// cloaked, see above
+	state.EffectiveValues = types.StringNull()
+	if state.SetWORevision.ValueInt64() <= 0 {
+		var evDiags diag.Diagnostics
+		state.EffectiveValues, evDiags = effectiveValues(r.Chart, r.Config, func(values map[string]interface{}) {
+			cloakSetValues(values, state)
+		})
+		diags.Append(evDiags...)
+		if diags.HasError() {
+			return diags
+		}
 	}
 
 	// Convert the list of ObjectValues to a ListValue
@@ -1834,6 +2803,22 @@ func mapToTerraformStringMap(ctx context.Context, m map[string]string) (types.Ma
 	return types.MapValue(types.StringType, valueMap)
 }
 
+// releaseMetadataAttributes returns the attributes of the metadata object for a release
+func releaseMetadataAttributes(r *release.Release, values types.String) map[string]attr.Value {
+	return map[string]attr.Value{
+		"name":           types.StringValue(r.Name),
+		"revision":       types.Int64Value(int64(r.Version)),
+		"namespace":      types.StringValue(r.Namespace),
+		"chart":          types.StringValue(r.Chart.Metadata.Name),
+		"version":        types.StringValue(r.Chart.Metadata.Version),
+		"app_version":    types.StringValue(r.Chart.Metadata.AppVersion),
+		"values":         values,
+		"first_deployed": types.Int64Value(r.Info.FirstDeployed.Unix()),
+		"last_deployed":  types.Int64Value(r.Info.LastDeployed.Unix()),
+		"notes":          types.StringValue(r.Info.Notes),
+	}
+}
+
 func metadataAttrTypes() map[string]attr.Type {
 	return map[string]attr.Type{
 		"name":           types.StringType,
@@ -2010,6 +2995,14 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 	logID := fmt.Sprintf("[resourceDiff: %s]", plan.Name.ValueString())
 	tflog.Debug(ctx, fmt.Sprintf("%s Start", logID))
 
+	resp.Diagnostics.Append(validateOutputs(ctx, plan.Outputs)...)
+	resp.Diagnostics.Append(validateHealthChecks(ctx, plan.HealthChecks)...)
+	resp.Diagnostics.Append(validateRetainOnDestroy(ctx, plan.RetainOnDestroy)...)
+	resp.Diagnostics.Append(validateWaitStrategy(&plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
 	meta := r.meta
 	name := plan.Name.ValueString()
 	namespace := plan.Namespace.ValueString()
@@ -2035,6 +3028,14 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 	// Always set desired state to DEPLOYED
 	plan.Status = types.StringValue(release.StatusDeployed.String())
 
+	// Drift is restored by upgrading the release, as a three-way merge or a
+	// server-side apply reverts the fields of the manifest
+	if state != nil && plan.DriftDetection != nil && plan.DriftDetection.ForceUpdate.ValueBool() && len(state.Drift.Elements()) > 0 {
+		tflog.Debug(ctx, fmt.Sprintf("%s Drift detected, forcing an update", logID))
+		plan.Drift = noDrift(plan.DriftDetection)
+		markComputedReleaseAttributesUnknown(&plan)
+	}
+
 	if !useChartVersion(plan.Chart.ValueString(), plan.Repository.ValueString()) {
 		// Check if version has changed
 		if state != nil && !plan.Version.Equal(state.Version) {
@@ -2045,7 +3046,7 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 
 			if oldVersionStr != newVersionStr && newVersionStr != "" {
 				// Setting Metadata to a computed value
-				plan.Metadata = types.ObjectUnknown(metadataAttrTypes())
+				markComputedReleaseAttributesUnknown(&plan)
 			}
 		}
 	}
@@ -2075,9 +3076,58 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 			return
 		}
 	}
+	resp.Diagnostics.Append(validateWaitStrategyHooks(&plan, chart)...)
+
+	var valuesFiles []valuesFile
+	if valuesFilesUnknown(plan.ValuesFiles) {
+		plan.ValuesFilesSHA256 = types.MapUnknown(types.StringType)
+	} else {
+		valuesFiles, diags = readValuesFiles(ctx, meta, chart, plan.ValuesFiles)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		plan.ValuesFilesSHA256, diags = valuesFilesSHA256(ctx, plan.ValuesFiles, valuesFiles)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
+
+	// Warn about the defaults flipped by a new version of the same chart
+	if state != nil && plan.Chart.Equal(state.Chart) && plan.Repository.Equal(state.Repository) &&
+		!useChartVersion(plan.Chart.ValueString(), plan.Repository.ValueString()) &&
+		state.Version.ValueString() != "" && !versionsEqual(state.Version.ValueString(), chart.Metadata.Version) {
+		resp.Diagnostics.Append(chartDefaultsDiff(ctx, meta, actionConfig, &plan, state.Version.ValueString(), chart)...)
+	}
+
+	// Values reused from the previous release are only known at apply time
+	if !plan.ReuseValues.ValueBool() && !valuesUnknown(plan) && !writeOnlyValuesUnknown(config) {
+		values, diags := getValues(ctx, &plan, valuesFiles)
+		resp.Diagnostics.Append(diags...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+		sources := releaseValuesSources(&plan, valuesFiles)
+
+		if config.SetWORevision.ValueInt64() > 0 {
+			woValues, diags := getWriteOnlyValues(ctx, &config)
+			resp.Diagnostics.Append(diags...)
+			if resp.Diagnostics.HasError() {
+				return
+			}
+			values = mergeMaps(values, woValues)
+			sources = append(sources, writeOnlyValuesSources(&config)...)
+		}
+
+		resp.Diagnostics.Append(validateValuesSchema(chart, values, sources)...)
+		if resp.Diagnostics.HasError() {
+			return
+		}
+	}
 
 	if plan.Lint.ValueBool() {
-		diags := resourceReleaseValidate(ctx, &plan, meta, cpo)
+		diags := resourceReleaseValidate(ctx, &plan, meta, cpo, valuesFiles)
 		if diags.HasError() {
 			resp.Diagnostics.Append(diags...)
 			return
@@ -2098,25 +3148,12 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 			return
 		}
 
-		if plan.PostRender != nil {
-			binaryPath := plan.PostRender.BinaryPath.ValueString()
-			argsList := plan.PostRender.Args.Elements()
-
-			if binaryPath != "" {
-				var args []string
-				for _, arg := range argsList {
-					args = append(args, arg.(basetypes.StringValue).ValueString())
-				}
-
-				pr, err := postrender.NewExec(binaryPath, args...)
-				if err != nil {
-					resp.Diagnostics.AddError("Error creating post-renderer", fmt.Sprintf("Could not create post-renderer: %s", err))
-					return
-				}
-
-				client.PostRenderer = pr
-			}
+		pr, prDiags := newPostRenderer(ctx, plan.PostRender)
+		resp.Diagnostics.Append(prDiags...)
+		if resp.Diagnostics.HasError() {
+			return
 		}
+		client.PostRenderer = pr
 		if state == nil {
 			install := action.NewInstall(actionConfig)
 			install.ChartPathOptions = *cpo
@@ -2139,7 +3176,7 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 			install.CreateNamespace = plan.CreateNamespace.ValueBool()
 			install.PostRenderer = client.PostRenderer
 
-			values, diags := getValues(ctx, &plan)
+			values, diags := getValues(ctx, &plan, valuesFiles)
 			resp.Diagnostics.Append(diags...)
 			if resp.Diagnostics.HasError() {
 				return
@@ -2229,7 +3266,7 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 		upgrade.Description = plan.Description.ValueString()
 		upgrade.PostRenderer = client.PostRenderer
 
-		values, diags := getValues(ctx, &plan)
+		values, diags := getValues(ctx, &plan, valuesFiles)
 		resp.Diagnostics.Append(diags...)
 		if resp.Diagnostics.HasError() {
 			return
@@ -2285,7 +3322,7 @@ func (r *HelmRelease) ModifyPlan(ctx context.Context, req resource.ModifyPlanReq
 		tflog.Debug(ctx, fmt.Sprintf("%s set manifest: %s", logID, jsonManifest))
 
 		if !state.Resources.Equal(plan.Resources) {
-			plan.Metadata = types.ObjectUnknown(metadataAttrTypes())
+			markComputedReleaseAttributesUnknown(&plan)
 		}
 
 	} else {
@@ -2336,12 +3373,20 @@ You should update the version in your configuration to %[2]q, or remove the vers
 
 	if recomputeMetadata(plan, state) {
 		tflog.Debug(ctx, fmt.Sprintf("%s Metadata has changes, setting to unknown", logID))
-		plan.Metadata = types.ObjectUnknown(metadataAttrTypes())
+		markComputedReleaseAttributesUnknown(&plan)
 	}
 
 	resp.Plan.Set(ctx, &plan)
 }
 
+// markComputedReleaseAttributesUnknown marks the attributes computed from the
+// deployed release as unknown, as they change when the release is upgraded
+func markComputedReleaseAttributesUnknown(plan *HelmReleaseModel) {
+	plan.Metadata = types.ObjectUnknown(metadataAttrTypes())
+	plan.EffectiveValues = types.StringUnknown()
+	plan.OutputValues = types.MapUnknown(types.StringType)
+}
+
 // TODO: write unit test, always returns true for recomputing the metadata
 // returns true if any metadata fields have changed
 func recomputeMetadata(plan HelmReleaseModel, state *HelmReleaseModel) bool {
@@ -2361,6 +3406,18 @@ func recomputeMetadata(plan HelmReleaseModel, state *HelmReleaseModel) bool {
 	if !plan.Values.Equal(state.Values) {
 		return true
 	}
+	if !plan.ValuesFiles.Equal(state.ValuesFiles) {
+		return true
+	}
+	if !listMergeEqual(plan.ListMerge, state.ListMerge) {
+		return true
+	}
+	if !plan.ValuesFilesSHA256.Equal(state.ValuesFilesSHA256) {
+		return true
+	}
+	if !plan.ValuesObject.Equal(state.ValuesObject) {
+		return true
+	}
 	if !plan.Set.Equal(state.Set) {
 		return true
 	}
@@ -2373,7 +3430,7 @@ func recomputeMetadata(plan HelmReleaseModel, state *HelmReleaseModel) bool {
 	return false
 }
 
-func resourceReleaseValidate(ctx context.Context, model *HelmReleaseModel, meta *Meta, cpo *action.ChartPathOptions) diag.Diagnostics {
+func resourceReleaseValidate(ctx context.Context, model *HelmReleaseModel, meta *Meta, cpo *action.ChartPathOptions, valuesFiles []valuesFile) diag.Diagnostics {
 	var diags diag.Diagnostics
 
 	cpo, name, chartDiags := chartPathOptions(model, meta, cpo)
@@ -2383,7 +3440,7 @@ func resourceReleaseValidate(ctx context.Context, model *HelmReleaseModel, meta
 		return diags
 	}
 
-	values, valuesDiags := getValues(ctx, model)
+	values, valuesDiags := getValues(ctx, model, valuesFiles)
 	diags.Append(valuesDiags...)
 	if diags.HasError() {
 		return diags
@@ -2530,6 +3587,12 @@ func (r *HelmRelease) ImportState(ctx context.Context, req resource.ImportStateR
 		},
 	})
 	state.Values = types.ListNull(types.StringType)
+	state.ValuesFiles = types.ListNull(types.StringType)
+	state.ValuesFilesSHA256 = types.MapNull(types.StringType)
+	state.ValuesObject = types.DynamicNull()
+	state.Outputs = types.MapNull(types.ObjectType{AttrTypes: outputAttrTypes()})
+	state.HealthChecks = types.ListNull(types.ObjectType{AttrTypes: healthCheckAttrTypes()})
+	state.RetainOnDestroy = types.ListNull(types.ObjectType{AttrTypes: retainOnDestroyAttrTypes()})
 
 	tflog.Debug(ctx, fmt.Sprintf("Setting final state: %+v", state))
 	diags = resp.State.Set(ctx, &state)
@@ -2562,11 +3625,17 @@ func parseImportIdentifier(id string) (string, string, error) {
 	return parts[0], parts[1], nil
 }
 
-// returns true if any values, set_list, set, set_sensitive are unknown
+// returns true if any values, values_files, values_object, set_list, set, set_sensitive are unknown
 func valuesUnknown(plan HelmReleaseModel) bool {
 	if plan.Values.IsUnknown() {
 		return true
 	}
+	if valuesFilesUnknown(plan.ValuesFiles) {
+		return true
+	}
+	if dynamicValueUnknown(plan.ValuesObject) {
+		return true
+	}
 	if plan.SetList.IsUnknown() {
 		return true
 	}
@@ -2577,6 +3646,12 @@ func valuesUnknown(plan HelmReleaseModel) bool {
 		return true
 	}
 
+	for _, v := range plan.Values.Elements() {
+		if v.IsUnknown() {
+			return true
+		}
+	}
+
 	sensitive := []setResourceModel{}
 	plan.SetSensitive.ElementsAs(context.Background(), &sensitive, false)
 	for _, s := range sensitive {
@@ -2604,6 +3679,23 @@ func valuesUnknown(plan HelmReleaseModel) bool {
 	return false
 }
 
+// returns true if set_wo or any of its values are unknown
+func writeOnlyValuesUnknown(config HelmReleaseModel) bool {
+	if config.SetWO.IsUnknown() {
+		return true
+	}
+
+	setWO := []setResourceModel{}
+	config.SetWO.ElementsAs(context.Background(), &setWO, false)
+	for _, s := range setWO {
+		if s.Name.IsUnknown() || s.Value.IsUnknown() {
+			return true
+		}
+	}
+
+	return false
+}
+
 func isInternalAnno(key string) bool {
 	u, err := url.Parse("//" + key)
 	if err != nil {
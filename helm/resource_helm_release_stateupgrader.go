@@ -10,6 +10,202 @@ import (
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
+// unversionedAttributeTypes holds the types of the attributes added to the
+// schema since version 2. They are optional and null in upgraded states.
+var unversionedAttributeTypes = map[string]tftypes.Type{
+	"drift": tftypes.List{
+		ElementType: tftypes.Object{
+			AttributeTypes: map[string]tftypes.Type{
+				"api_version": tftypes.String,
+				"deleted":     tftypes.Bool,
+				"fields": tftypes.List{
+					ElementType: tftypes.Object{
+						AttributeTypes: map[string]tftypes.Type{
+							"actual":   tftypes.String,
+							"expected": tftypes.String,
+							"path":     tftypes.String,
+						},
+					},
+				},
+				"kind":      tftypes.String,
+				"name":      tftypes.String,
+				"namespace": tftypes.String,
+			},
+		},
+	},
+	"drift_detection": tftypes.Object{
+		AttributeTypes: map[string]tftypes.Type{
+			"force_update":  tftypes.Bool,
+			"ignore_fields": tftypes.List{ElementType: tftypes.String},
+		},
+	},
+	"effective_values": tftypes.String,
+	"health_checks": tftypes.List{
+		ElementType: tftypes.Object{
+			AttributeTypes: map[string]tftypes.Type{
+				"api_version": tftypes.String,
+				"condition":   tftypes.String,
+				"json_path":   tftypes.String,
+				"kind":        tftypes.String,
+				"name":        tftypes.String,
+				"namespace":   tftypes.String,
+				"status":      tftypes.String,
+				"value":       tftypes.String,
+			},
+		},
+	},
+	"list_merge": tftypes.Object{
+		AttributeTypes: map[string]tftypes.Type{
+			"key":      tftypes.String,
+			"strategy": tftypes.String,
+		},
+	},
+	"output_values": tftypes.Map{ElementType: tftypes.String},
+	"outputs": tftypes.Map{
+		ElementType: tftypes.Object{
+			AttributeTypes: map[string]tftypes.Type{
+				"json_path": tftypes.String,
+				"kind":      tftypes.String,
+				"name":      tftypes.String,
+				"namespace": tftypes.String,
+				"source":    tftypes.String,
+			},
+		},
+	},
+	"retain_on_destroy": tftypes.List{
+		ElementType: tftypes.Object{
+			AttributeTypes: map[string]tftypes.Type{
+				"kind":           tftypes.String,
+				"label_selector": tftypes.String,
+				"name":           tftypes.String,
+			},
+		},
+	},
+	"rollback_on_failure": tftypes.Object{
+		AttributeTypes: map[string]tftypes.Type{
+			"revision":      tftypes.Number,
+			"timeout":       tftypes.Number,
+			"wait":          tftypes.Bool,
+			"wait_for_jobs": tftypes.Bool,
+		},
+	},
+	"server_side_apply": tftypes.Object{
+		AttributeTypes: map[string]tftypes.Type{
+			"field_manager":   tftypes.String,
+			"force_conflicts": tftypes.Bool,
+		},
+	},
+	"test": tftypes.Object{
+		AttributeTypes: map[string]tftypes.Type{
+			"filter":           tftypes.List{ElementType: tftypes.String},
+			"taint_on_failure": tftypes.Bool,
+			"timeout":          tftypes.Number,
+		},
+	},
+	"uninstall": tftypes.Object{
+		AttributeTypes: map[string]tftypes.Type{
+			"deletion_propagation": tftypes.String,
+			"description":          tftypes.String,
+			"keep_history":         tftypes.Bool,
+			"skip":                 tftypes.Bool,
+			"timeout":              tftypes.Number,
+		},
+	},
+	"values_files":        tftypes.List{ElementType: tftypes.String},
+	"values_files_sha256": tftypes.Map{ElementType: tftypes.String},
+	"values_object":       tftypes.DynamicPseudoType,
+	"wait_strategy":       tftypes.String,
+}
+
+// unversionedPostRenderAttributeTypes holds the types of the postrender
+// attributes added since version 2. They are null in upgraded states.
+var unversionedPostRenderAttributeTypes = map[string]tftypes.Type{
+	"env":           tftypes.Map{ElementType: tftypes.String},
+	"env_sensitive": tftypes.Map{ElementType: tftypes.String},
+	"kustomize":     kustomizeAttributeType,
+	"steps": tftypes.List{
+		ElementType: tftypes.Object{
+			AttributeTypes: map[string]tftypes.Type{
+				"args":          tftypes.List{ElementType: tftypes.String},
+				"binary_path":   tftypes.String,
+				"env":           tftypes.Map{ElementType: tftypes.String},
+				"env_sensitive": tftypes.Map{ElementType: tftypes.String},
+				"kustomize":     kustomizeAttributeType,
+				"timeout":       tftypes.Number,
+				"working_dir":   tftypes.String,
+			},
+		},
+	},
+	"timeout":     tftypes.Number,
+	"working_dir": tftypes.String,
+}
+
+// kustomizeAttributeType is the type of the kustomize attribute of
+// postrender blocks.
+var kustomizeAttributeType = tftypes.Object{
+	AttributeTypes: map[string]tftypes.Type{
+		"common_annotations": tftypes.Map{ElementType: tftypes.String},
+		"common_labels":      tftypes.Map{ElementType: tftypes.String},
+		"images": tftypes.List{
+			ElementType: tftypes.Object{
+				AttributeTypes: map[string]tftypes.Type{
+					"digest":   tftypes.String,
+					"name":     tftypes.String,
+					"new_name": tftypes.String,
+					"new_tag":  tftypes.String,
+				},
+			},
+		},
+		"kustomization": tftypes.String,
+		"patches": tftypes.List{
+			ElementType: tftypes.Object{
+				AttributeTypes: map[string]tftypes.Type{
+					"patch": tftypes.String,
+					"target": tftypes.Object{
+						AttributeTypes: map[string]tftypes.Type{
+							"annotation_selector": tftypes.String,
+							"group":               tftypes.String,
+							"kind":                tftypes.String,
+							"label_selector":      tftypes.String,
+							"name":                tftypes.String,
+							"namespace":           tftypes.String,
+							"version":             tftypes.String,
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+// addUnversionedAttributes adds the attributes of unversionedAttributeTypes
+// and unversionedPostRenderAttributeTypes to the type and values of an
+// upgraded state.
+func addUnversionedAttributes(newType tftypes.Object, newValues map[string]tftypes.Value) error {
+	for name, typ := range unversionedAttributeTypes {
+		newType.AttributeTypes[name] = typ
+		newValues[name] = tftypes.NewValue(typ, nil)
+	}
+
+	var postrender map[string]tftypes.Value
+	if err := newValues["postrender"].As(&postrender); err != nil {
+		return err
+	}
+	postrenderType := newType.AttributeTypes["postrender"].(tftypes.Object)
+	for name, typ := range unversionedPostRenderAttributeTypes {
+		postrenderType.AttributeTypes[name] = typ
+		if postrender != nil {
+			postrender[name] = tftypes.NewValue(typ, nil)
+		}
+	}
+	if postrender == nil {
+		newValues["postrender"] = tftypes.NewValue(postrenderType, nil)
+	} else {
+		newValues["postrender"] = tftypes.NewValue(postrenderType, postrender)
+	}
+	return nil
+}
+
 func (r *HelmRelease) buildUpgradeStateMap(_ context.Context) map[int64]resource.StateUpgrader {
 	return map[int64]resource.StateUpgrader{
 		0: {
@@ -310,7 +506,7 @@ func (r *HelmRelease) buildUpgradeStateMap(_ context.Context) map[int64]resource
 					defaultAttributes["pass_credentials"],
 				)
 
-				newValue := tftypes.NewValue(newType, map[string]tftypes.Value{
+				newValues := map[string]tftypes.Value{
 					"metadata": tftypes.NewValue(
 						newType.AttributeTypes["metadata"],
 						newMetadata,
@@ -363,7 +559,12 @@ func (r *HelmRelease) buildUpgradeStateMap(_ context.Context) map[int64]resource
 					"version":                    oldState["version"],
 					"wait":                       oldState["wait"],
 					"wait_for_jobs":              oldState["wait_for_jobs"],
-				})
+				}
+				if err := addUnversionedAttributes(newType, newValues); err != nil {
+					resp.Diagnostics.AddError("Failed to construct upgraded state", err.Error())
+					return
+				}
+				newValue := tftypes.NewValue(newType, newValues)
 
 				dv, err := tfprotov6.NewDynamicValue(newType, newValue)
 				if err != nil {
@@ -632,7 +833,7 @@ func (r *HelmRelease) buildUpgradeStateMap(_ context.Context) map[int64]resource
 						"wait_for_jobs":   tftypes.Bool,
 					},
 				}
-				newValue := tftypes.NewValue(newType, map[string]tftypes.Value{
+				newValues := map[string]tftypes.Value{
 					"metadata": tftypes.NewValue(newType.AttributeTypes["metadata"], metadata),
 					"postrender": tftypes.NewValue(
 						newType.AttributeTypes["postrender"],
@@ -688,7 +889,12 @@ func (r *HelmRelease) buildUpgradeStateMap(_ context.Context) map[int64]resource
 					"version":                    oldState["version"],
 					"wait":                       oldState["wait"],
 					"wait_for_jobs":              oldState["wait_for_jobs"],
-				})
+				}
+				if err := addUnversionedAttributes(newType, newValues); err != nil {
+					resp.Diagnostics.AddError("Failed to construct upgraded state", err.Error())
+					return
+				}
+				newValue := tftypes.NewValue(newType, newValues)
 
 				dv, err := tfprotov6.NewDynamicValue(newType, newValue)
 				if err != nil {
@@ -4,8 +4,11 @@
 package helm
 
 import (
+	"context"
 	"testing"
 
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-go/tfprotov6"
 	"github.com/hashicorp/terraform-plugin-go/tftypes"
 )
 
@@ -497,3 +500,82 @@ func containsHelper(s, substr string) bool {
 	}
 	return false
 }
+
+// TestStateUpgraderV1_MatchesCurrentSchema tests that the state produced by
+// the version 1 StateUpgrader can be decoded with the current schema, which
+// fails if an attribute was added to the schema but not to the upgrader.
+func TestStateUpgraderV1_MatchesCurrentSchema(t *testing.T) {
+	ctx := context.Background()
+	stateJSON := `{
+		"metadata": [{
+			"name": "test",
+			"namespace": "default",
+			"revision": 1,
+			"version": "1.0.0",
+			"chart": "nginx",
+			"app_version": "1.0.0",
+			"values": "{}",
+			"first_deployed": 1234567890,
+			"last_deployed": 1234567890,
+			"notes": "test notes"
+		}],
+		"postrender": [{"binary_path": "kustomize", "args": ["build"]}],
+		"set": [],
+		"set_list": [],
+		"set_sensitive": [],
+		"atomic": false,
+		"chart": "nginx",
+		"cleanup_on_fail": false,
+		"create_namespace": false,
+		"dependency_update": false,
+		"description": "",
+		"devel": false,
+		"disable_crd_hooks": false,
+		"disable_openapi_validation": false,
+		"disable_webhooks": false,
+		"force_update": false,
+		"id": "test",
+		"keyring": "",
+		"lint": false,
+		"manifest": "",
+		"max_history": 0,
+		"name": "test",
+		"namespace": "default",
+		"pass_credentials": false,
+		"recreate_pods": false,
+		"render_subchart_notes": true,
+		"replace": false,
+		"repository": "",
+		"repository_ca_file": "",
+		"repository_cert_file": "",
+		"repository_key_file": "",
+		"repository_password": "",
+		"repository_username": "",
+		"reset_values": false,
+		"reuse_values": false,
+		"skip_crds": false,
+		"status": "deployed",
+		"timeout": 300,
+		"upgrade_install": false,
+		"values": [],
+		"verify": false,
+		"version": "1.0.0",
+		"wait": true,
+		"wait_for_jobs": false
+	}`
+
+	r := &HelmRelease{}
+	upgrader := r.buildUpgradeStateMap(ctx)[1]
+	req := resource.UpgradeStateRequest{RawState: &tfprotov6.RawState{JSON: []byte(stateJSON)}}
+	resp := &resource.UpgradeStateResponse{}
+	upgrader.StateUpgrader(ctx, req, resp)
+	if resp.Diagnostics.HasError() {
+		t.Fatalf("Failed to upgrade state: %v", resp.Diagnostics)
+	}
+
+	schemaResp := &resource.SchemaResponse{}
+	r.Schema(ctx, resource.SchemaRequest{}, schemaResp)
+	if _, err := resp.DynamicValue.Unmarshal(schemaResp.Schema.Type().TerraformType(ctx)); err != nil {
+		t.Fatalf("Upgraded state does not match the current schema: %v", err)
+	}
+}
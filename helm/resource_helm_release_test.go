@@ -13,6 +13,7 @@ import (
 	"os/exec"
 	"path"
 	"path/filepath"
+	"reflect"
 	"regexp"
 	"strconv"
 	"strings"
@@ -20,6 +21,7 @@ import (
 	"time"
 
 	"github.com/hashicorp/go-version"
+	"github.com/hashicorp/terraform-plugin-go/tftypes"
 	"github.com/hashicorp/terraform-plugin-testing/helper/acctest"
 	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
 	"github.com/hashicorp/terraform-plugin-testing/knownvalue"
@@ -388,6 +390,46 @@ func TestAccResourceRelease_updateValues(t *testing.T) {
 	})
 }
 
+func TestAccResourceRelease_valuesObject(t *testing.T) {
+	name := randName("test-values-object")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigValuesObject(testResourceName, namespace, name, `{
+					foo      = "baz"
+					replicas = 2
+					labels   = { tier = "web" }
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "values_object.replicas", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.values", `{"foo":"qux","labels":{"tier":"web"},"replicas":2}`),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigValuesObject(testResourceName, namespace, name, `{
+					replicas = 3
+					labels   = { tier = "web", team = "platform" }
+					ports    = [80, 443]
+				}`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.values", `{"foo":"qux","labels":{"team":"platform","tier":"web"},"ports":[80,443],"replicas":3}`),
+				),
+			},
+			{
+				Config:      testAccHelmReleaseConfigValuesObject(testResourceName, namespace, name, `["foo"]`),
+				ExpectError: regexp.MustCompile("values_object must be an object or a map"),
+			},
+		},
+	})
+}
+
 func TestAccResourceRelease_cloakValues(t *testing.T) {
 	name := randName("test-update-values")
 	namespace := createRandomNamespace(t)
@@ -434,6 +476,40 @@ func TestAccResourceRelease_cloakValues(t *testing.T) {
 	})
 }
 
+func TestAccResourceRelease_effectiveValues(t *testing.T) {
+	name := randName("test-effective-values")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigSensitiveValue(
+					testResourceName, namespace, name, "test-chart", "1.2.3", "cloakedData.cloaked", "foobar",
+				),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.values", `{"cloakedData":{"cloaked":"(sensitive value)"}}`),
+					resource.TestCheckResourceAttrWith("helm_release.test", "effective_values", func(value string) error {
+						var values map[string]interface{}
+						if err := json.Unmarshal([]byte(value), &values); err != nil {
+							return err
+						}
+						// Chart defaults are included, sensitive values are cloaked
+						if port := values["service"].(map[string]interface{})["port"]; port != float64(80) {
+							return fmt.Errorf("expected the default service port 80, got %v", port)
+						}
+						if cloaked := values["cloakedData"].(map[string]interface{})["cloaked"]; cloaked != "(sensitive value)" {
+							return fmt.Errorf("expected the sensitive value to be cloaked, got %v", cloaked)
+						}
+						return nil
+					}),
+				),
+			},
+		},
+	})
+}
+
 func TestAccResourceRelease_updateMultipleValues(t *testing.T) {
 	name := randName("test-update-multiple-values")
 	namespace := createRandomNamespace(t)
@@ -828,6 +904,116 @@ func TestAccResourceRelease_updateExistingFailed(t *testing.T) {
 	})
 }
 
+func TestAccResourceRelease_rollbackOnFailure(t *testing.T) {
+	name := randName("test-rollback-on-failure")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			// Step 1: Create release successfully
+			{
+				Config: testAccHelmReleaseConfigValues(
+					testResourceName, namespace, name, "test-chart", "1.2.3",
+					[]string{"serviceAccount:\n  name: valid-name"},
+				),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				),
+			},
+			// Step 2: Leave the release in FAILED state without rolling back
+			{
+				Config: testAccHelmReleaseConfigValues(
+					testResourceName, namespace, name, "test-chart", "1.2.3",
+					[]string{"service:\n  type: invalid%-$type"},
+				),
+				ExpectError:        regexp.MustCompile("Unsupported value"),
+				ExpectNonEmptyPlan: true,
+			},
+			// Step 3: The failed upgrade (revision 3) is rolled back to revision 1,
+			// skipping revision 2 which had already failed
+			{
+				Config:             testAccHelmReleaseConfigRollbackOnFailure(testResourceName, namespace, name, "service:\n  type: invalid%-$type"),
+				ExpectError:        regexp.MustCompile("Helm release upgrade failed"),
+				ExpectNonEmptyPlan: true,
+			},
+			{
+				RefreshState: true,
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "4"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				),
+			},
+			// Step 4: Fix the configuration
+			{
+				Config: testAccHelmReleaseConfigRollbackOnFailure(testResourceName, namespace, name, "serviceAccount:\n  name: recovered-name"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "5"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRelease_test(t *testing.T) {
+	name := randName("test-release-test")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigTest(testResourceName, namespace, name, "1.2.3", `[]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "test.taint_on_failure", "true"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigTest(testResourceName, namespace, name, "2.0.0", `["!does-not-exist"]`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				),
+			},
+		},
+	})
+}
+
+func TestAccResourceRelease_serverSideApply(t *testing.T) {
+	name := randName("test-server-side-apply")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigServerSideApply(testResourceName, namespace, name, "1.2.3"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "server_side_apply.field_manager", "terraform-acc-test"),
+					resource.TestCheckResourceAttr("helm_release.test", "server_side_apply.force_conflicts", "true"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigServerSideApply(testResourceName, namespace, name, "2.0.0"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.version", "2.0.0"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+				),
+			},
+		},
+	})
+}
+
 // TestAccResourceRelease_statePreservedDuringRefresh tests:
 // 1: State not removed during refresh - verifies that when a release exists
 // in both Terraform state and Kubernetes cluster, terraform refresh/plan
@@ -1129,6 +1315,111 @@ func TestAccResourceRelease_deleteAlreadyRemovedRelease(t *testing.T) {
 	})
 }
 
+// TestAccResourceRelease_uninstallKeepHistory verifies that the history of a
+// release uninstalled with keep_history is kept, with the description of the
+// uninstallation
+func TestAccResourceRelease_uninstallKeepHistory(t *testing.T) {
+	name := randName("uninstall-keep-history")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy:             testAccCheckHelmReleaseUninstalled(namespace, name, release.StatusUninstalled, "Decommissioned"),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigUninstall(testResourceName, namespace, name, `
+					keep_history         = true
+					deletion_propagation = "foreground"
+					description          = "Decommissioned"
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "uninstall.keep_history", "true"),
+					resource.TestCheckResourceAttr("helm_release.test", "uninstall.skip", "false"),
+				),
+			},
+		},
+	})
+}
+
+// TestAccResourceRelease_uninstallSkip verifies that a release whose
+// uninstallation is skipped is only removed from the state
+func TestAccResourceRelease_uninstallSkip(t *testing.T) {
+	name := randName("uninstall-skip")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy:             testAccCheckHelmReleaseUninstalled(namespace, name, release.StatusDeployed, ""),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigUninstall(testResourceName, namespace, name, `
+					skip = false
+				`),
+				Check: resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+			},
+			{
+				// Changing the uninstall options doesn't upgrade the release
+				Config: testAccHelmReleaseConfigUninstall(testResourceName, namespace, name, `
+					skip = true
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "uninstall.skip", "true"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigUninstall(resource, ns, name, uninstall string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+
+			uninstall = {
+				%s
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, uninstall)
+}
+
+// testAccCheckHelmReleaseUninstalled checks the status and the description
+// of the last revision of a release after it is destroyed
+func testAccCheckHelmReleaseUninstalled(namespace, name string, status release.Status, description string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		if testMeta == nil {
+			return fmt.Errorf("provider not properly initialized")
+		}
+		actionConfig, err := testMeta.GetHelmConfiguration(context.Background(), namespace)
+		if err != nil {
+			return err
+		}
+		history, err := action.NewHistory(actionConfig).Run(name)
+		if err != nil {
+			return fmt.Errorf("expected the history of release %q to be kept: %w", name, err)
+		}
+		last := history[0]
+		for _, r := range history {
+			if r.Version > last.Version {
+				last = r
+			}
+		}
+		if last.Info.Status != status {
+			return fmt.Errorf("expected release %q to be %s, got %s", name, status, last.Info.Status)
+		}
+		if description != "" && last.Info.Description != description {
+			return fmt.Errorf("expected the description of release %q to be %q, got %q", name, description, last.Info.Description)
+		}
+		return nil
+	}
+}
+
 func TestAccResourceRelease_SetNull(t *testing.T) {
 	name := randName("test-update-set-value")
 	namespace := createRandomNamespace(t)
@@ -1281,6 +1572,28 @@ func TestAccResourceRelease_postrender(t *testing.T) {
 	})
 }
 
+func TestAccResourceRelease_postrenderSteps(t *testing.T) {
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigPostrenderSteps(testResourceName, namespace, testResourceName, "cat"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "postrender.steps.#", "2"),
+				),
+			},
+			{
+				Config:      testAccHelmReleaseConfigPostrenderSteps(testResourceName, namespace, testResourceName, "foobardoesnotexist"),
+				ExpectError: regexp.MustCompile("unable to find binary"),
+			},
+		},
+	})
+}
+
 func TestAccResourceRelease_namespaceDoesNotExist(t *testing.T) {
 	name := randName("test-namespace-does-not-exist")
 	namespace := createRandomNamespace(t)
@@ -1548,6 +1861,78 @@ func testAccHelmReleaseConfigValues(resource, ns, name, chart, version string, v
 	`, resource, name, ns, testRepositoryURL, chart, version, strings.Join(vals, ","))
 }
 
+func testAccHelmReleaseConfigValuesObject(resource, ns, name, valuesObject string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name          = %q
+			namespace     = %q
+			repository    = %q
+			chart         = "test-chart"
+			version       = "1.2.3"
+			values        = ["foo: bar"]
+			values_object = %s
+
+			set = [
+				{
+					name  = "foo"
+					value = "qux"
+				}
+			]
+		}
+	`, resource, name, ns, testRepositoryURL, valuesObject)
+}
+
+func testAccHelmReleaseConfigRollbackOnFailure(resource, ns, name, values string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+			values     = [ %q ]
+
+			rollback_on_failure = {
+				timeout = 60
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, values)
+}
+
+func testAccHelmReleaseConfigTest(resource, ns, name, version, filter string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = %q
+
+			test = {
+				filter  = %s
+				timeout = 120
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, version, filter)
+}
+
+func testAccHelmReleaseConfigServerSideApply(resource, ns, name, version string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = %q
+
+			server_side_apply = {
+				field_manager   = "terraform-acc-test"
+				force_conflicts = true
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, version)
+}
+
 func testAccHelmReleaseConfigSensitiveValue(resource, ns, name, chart, version, key, value string) string {
 	return fmt.Sprintf(`
 		resource "helm_release" "%s" {
@@ -1681,6 +2066,80 @@ func testAccHelmReleaseConfigSetNull(resource, ns, name, version string) string
 // 	}
 // }
 
+func TestOnlyDestroyOptionsChanged(t *testing.T) {
+	objectType := tftypes.Object{AttributeTypes: map[string]tftypes.Type{
+		"chart":               tftypes.String,
+		"metadata":            tftypes.String,
+		"uninstall":           tftypes.Object{AttributeTypes: map[string]tftypes.Type{"skip": tftypes.Bool}},
+		"values_files_sha256": tftypes.String,
+	}}
+	value := func(chart, metadata interface{}, skip bool, sha256 interface{}) tftypes.Value {
+		return tftypes.NewValue(objectType, map[string]tftypes.Value{
+			"chart":               tftypes.NewValue(tftypes.String, chart),
+			"metadata":            tftypes.NewValue(tftypes.String, metadata),
+			"uninstall":           tftypes.NewValue(objectType.AttributeTypes["uninstall"], map[string]tftypes.Value{"skip": tftypes.NewValue(tftypes.Bool, skip)}),
+			"values_files_sha256": tftypes.NewValue(tftypes.String, sha256),
+		})
+	}
+	state := value("app", "revision 1", false, "abc")
+
+	tests := map[string]struct {
+		plan     tftypes.Value
+		expected bool
+	}{
+		"no change":                    {value("app", "revision 1", false, "abc"), false},
+		"uninstall":                    {value("app", tftypes.UnknownValue, true, "abc"), true},
+		"uninstall and chart":          {value("other", tftypes.UnknownValue, true, "abc"), false},
+		"uninstall and values files":   {value("app", tftypes.UnknownValue, true, tftypes.UnknownValue), false},
+		"chart":                        {value("other", tftypes.UnknownValue, false, "abc"), false},
+		"uninstall and known metadata": {value("app", "revision 2", true, "abc"), false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if changed := onlyDestroyOptionsChanged(tt.plan, state); changed != tt.expected {
+				t.Errorf("expected %t, got %t", tt.expected, changed)
+			}
+		})
+	}
+}
+
+func TestReleaseTestFilters(t *testing.T) {
+	filters := releaseTestFilters([]string{"test-connection", "!test-slow", "test-db"})
+
+	expected := map[string][]string{
+		action.IncludeNameFilter: {"test-connection", "test-db"},
+		action.ExcludeNameFilter: {"test-slow"},
+	}
+	if !reflect.DeepEqual(filters, expected) {
+		t.Fatalf("expected %v, got %v", expected, filters)
+	}
+}
+
+func TestLastDeployedRevision(t *testing.T) {
+	history := []*release.Release{
+		{Version: 1, Info: &release.Info{Status: release.StatusSuperseded}},
+		{Version: 2, Info: &release.Info{Status: release.StatusDeployed}},
+		{Version: 3, Info: &release.Info{Status: release.StatusFailed}},
+		{Version: 4, Info: &release.Info{Status: release.StatusFailed}},
+	}
+
+	tests := []struct {
+		before   int
+		expected int
+	}{
+		{4, 2},
+		{3, 2},
+		{2, 1},
+		{1, 0},
+	}
+
+	for _, tt := range tests {
+		if got := lastDeployedRevision(history, tt.before); got != tt.expected {
+			t.Errorf("lastDeployedRevision(history, %d) = %d, expected %d", tt.before, got, tt.expected)
+		}
+	}
+}
+
 func TestUseChartVersion(t *testing.T) {
 	type test struct {
 		chartPath       string
@@ -2006,6 +2465,33 @@ func testAccHelmReleaseConfigPostrender(resource, ns, name, binaryPath string, a
 	`, resource, name, ns, testRepositoryURL, binaryPath, fmt.Sprintf(`"%s"`, strings.Join(args, `", "`)))
 }
 
+func testAccHelmReleaseConfigPostrenderSteps(resource, ns, name, binaryPath string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name        = %q
+			namespace   = %q
+			repository  = %q
+			chart       = "test-chart"
+			version     = "1.2.3"
+
+			postrender = {
+				steps = [
+					{
+						kustomize = {
+							common_labels = {
+								"postrender-step" = "kustomize"
+							}
+						}
+					},
+					{
+						binary_path = %q
+					}
+				]
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, binaryPath)
+}
+
 func TestAccResourceRelease_LintFailValues(t *testing.T) {
 	namespace := createRandomNamespace(t)
 	defer deleteNamespace(t, namespace)
@@ -3233,6 +3719,63 @@ func patchDeploymentPF(t *testing.T, namespace, name string, patchBytes []byte)
 	}
 }
 
+func TestAccResourceRelease_driftDetection(t *testing.T) {
+	name := randName("drift")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	fullName := fmt.Sprintf("%s-test-chart", name)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigDriftDetection(testResourceName, namespace, name, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "status", release.StatusDeployed.String()),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.#", "0"),
+				),
+			},
+			{
+				PreConfig: patchDeploymentPF(t, namespace, fullName, []byte(`{"spec":{"replicas":2}}`)),
+				Config:    testAccHelmReleaseConfigDriftDetection(testResourceName, namespace, name, false),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.#", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.0.kind", "Deployment"),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.0.name", fullName),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.0.fields.0.path", "spec.replicas"),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.0.fields.0.expected", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.0.fields.0.actual", "2"),
+				),
+			},
+			{
+				Config: testAccHelmReleaseConfigDriftDetection(testResourceName, namespace, name, true),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "2"),
+					resource.TestCheckResourceAttr("helm_release.test", "drift.#", "0"),
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigDriftDetection(resource, ns, name string, forceUpdate bool) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+
+			drift_detection = {
+				force_update = %t
+			}
+		}
+	`, resource, name, ns, testRepositoryURL, forceUpdate)
+}
+
 func TestAccResourceRelease_manifestServerDiff(t *testing.T) {
 	name := randName("serverdiff")
 	namespace := createRandomNamespace(t)
@@ -0,0 +1,440 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/gofrs/flock"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/resource"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/booldefault"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/planmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/resource/schema/stringplanmodifier"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/getter"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/registry"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+// repositoryLockTimeout is how long to wait for the lock of the repository
+// file, as `helm repo add` does
+const repositoryLockTimeout = 30 * time.Second
+
+var (
+	_ resource.Resource                = &HelmRepository{}
+	_ resource.ResourceWithConfigure   = &HelmRepository{}
+	_ resource.ResourceWithImportState = &HelmRepository{}
+)
+
+// HelmRepository manages an entry of the repository file configured with
+// repository_config_path, the equivalent of `helm repo add`
+type HelmRepository struct {
+	meta *Meta
+}
+
+func NewHelmRepository() resource.Resource {
+	return &HelmRepository{}
+}
+
+// HelmRepositoryModel holds the attributes of a chart repository
+type HelmRepositoryModel struct {
+	CaFile                types.String `tfsdk:"ca_file"`
+	CertFile              types.String `tfsdk:"cert_file"`
+	ID                    types.String `tfsdk:"id"`
+	InsecureSkipTLSVerify types.Bool   `tfsdk:"insecure_skip_tls_verify"`
+	KeyFile               types.String `tfsdk:"key_file"`
+	Name                  types.String `tfsdk:"name"`
+	PassCredentials       types.Bool   `tfsdk:"pass_credentials"`
+	Password              types.String `tfsdk:"password"`
+	URL                   types.String `tfsdk:"url"`
+	Username              types.String `tfsdk:"username"`
+}
+
+func (r *HelmRepository) Metadata(ctx context.Context, req resource.MetadataRequest, resp *resource.MetadataResponse) {
+	resp.TypeName = req.ProviderTypeName + "_repository"
+}
+
+func (r *HelmRepository) Schema(ctx context.Context, req resource.SchemaRequest, resp *resource.SchemaResponse) {
+	resp.Schema = schema.Schema{
+		Description: "Manages a chart repository in the Helm repository file, the same way as `helm repo add`",
+		Attributes: map[string]schema.Attribute{
+			"ca_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Verify certificates of HTTPS-enabled servers using this CA bundle",
+			},
+			"cert_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Identify HTTPS client using this SSL certificate file",
+			},
+			"id": schema.StringAttribute{
+				Computed: true,
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.UseStateForUnknown(),
+				},
+			},
+			"insecure_skip_tls_verify": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Skip TLS certificate checks of the repository",
+			},
+			"key_file": schema.StringAttribute{
+				Optional:    true,
+				Description: "Identify HTTPS client using this SSL key file",
+			},
+			"name": schema.StringAttribute{
+				Required:    true,
+				Description: "Name of the repository. Charts of the repository can be referred to as `name/chart`",
+				PlanModifiers: []planmodifier.String{
+					stringplanmodifier.RequiresReplace(),
+				},
+			},
+			"pass_credentials": schema.BoolAttribute{
+				Optional:    true,
+				Computed:    true,
+				Default:     booldefault.StaticBool(false),
+				Description: "Pass credentials to all domains",
+			},
+			"password": schema.StringAttribute{
+				Optional:    true,
+				Sensitive:   true,
+				Description: "Password for HTTP basic authentication",
+			},
+			"url": schema.StringAttribute{
+				Required:    true,
+				Description: "URL of the repository",
+			},
+			"username": schema.StringAttribute{
+				Optional:    true,
+				Description: "Username for HTTP basic authentication",
+			},
+		},
+	}
+}
+
+func (r *HelmRepository) Configure(ctx context.Context, req resource.ConfigureRequest, resp *resource.ConfigureResponse) {
+	if req.ProviderData == nil {
+		return
+	}
+
+	meta, ok := req.ProviderData.(*Meta)
+	if !ok {
+		resp.Diagnostics.AddError(
+			"Provider Configuration Error",
+			fmt.Sprintf("Unexpected ProviderData type: %T", req.ProviderData),
+		)
+		return
+	}
+	r.meta = meta
+}
+
+func (r *HelmRepository) Create(ctx context.Context, req resource.CreateRequest, resp *resource.CreateResponse) {
+	var plan HelmRepositoryModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.addRepository(ctx, &plan, false)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.Name
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *HelmRepository) Read(ctx context.Context, req resource.ReadRequest, resp *resource.ReadResponse) {
+	var state HelmRepositoryModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	meta := r.meta
+	if meta == nil {
+		resp.Diagnostics.AddError(
+			"Meta not set",
+			"The meta information is not set for the resource",
+		)
+		return
+	}
+
+	meta.Mutex.Lock()
+	f, err := loadRepositoryFile(meta.Settings.RepositoryConfig)
+	meta.Mutex.Unlock()
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading repository file",
+			fmt.Sprintf("Unable to read repository file %s: %s", meta.Settings.RepositoryConfig, err),
+		)
+		return
+	}
+
+	entry := f.Get(state.Name.ValueString())
+	if entry == nil {
+		tflog.Debug(ctx, fmt.Sprintf("Repository %q not found in %s, removing it from the state", state.Name.ValueString(), meta.Settings.RepositoryConfig))
+		resp.State.RemoveResource(ctx)
+		return
+	}
+
+	state.ID = types.StringValue(entry.Name)
+	state.URL = types.StringValue(entry.URL)
+	state.Username = optionalStringValue(state.Username, entry.Username)
+	state.Password = optionalStringValue(state.Password, entry.Password)
+	state.CaFile = optionalStringValue(state.CaFile, entry.CAFile)
+	state.CertFile = optionalStringValue(state.CertFile, entry.CertFile)
+	state.KeyFile = optionalStringValue(state.KeyFile, entry.KeyFile)
+	state.InsecureSkipTLSVerify = types.BoolValue(entry.InsecureSkipTLSverify)
+	state.PassCredentials = types.BoolValue(entry.PassCredentialsAll)
+
+	resp.Diagnostics.Append(resp.State.Set(ctx, &state)...)
+}
+
+func (r *HelmRepository) Update(ctx context.Context, req resource.UpdateRequest, resp *resource.UpdateResponse) {
+	var plan HelmRepositoryModel
+	resp.Diagnostics.Append(req.Plan.Get(ctx, &plan)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	resp.Diagnostics.Append(r.addRepository(ctx, &plan, true)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	plan.ID = plan.Name
+	resp.Diagnostics.Append(resp.State.Set(ctx, &plan)...)
+}
+
+func (r *HelmRepository) Delete(ctx context.Context, req resource.DeleteRequest, resp *resource.DeleteResponse) {
+	var state HelmRepositoryModel
+	resp.Diagnostics.Append(req.State.Get(ctx, &state)...)
+	if resp.Diagnostics.HasError() {
+		return
+	}
+
+	meta := r.meta
+	if meta == nil {
+		resp.Diagnostics.AddError(
+			"Meta not set",
+			"The meta information is not set for the resource",
+		)
+		return
+	}
+
+	name := state.Name.ValueString()
+	repositoryConfig := meta.Settings.RepositoryConfig
+
+	meta.Mutex.Lock()
+	defer meta.Mutex.Unlock()
+
+	unlock, err := lockRepositoryFile(ctx, repositoryConfig)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error locking repository file",
+			fmt.Sprintf("Unable to lock repository file %s: %s", repositoryConfig, err),
+		)
+		return
+	}
+	defer unlock()
+
+	f, err := loadRepositoryFile(repositoryConfig)
+	if err != nil {
+		resp.Diagnostics.AddError(
+			"Error reading repository file",
+			fmt.Sprintf("Unable to read repository file %s: %s", repositoryConfig, err),
+		)
+		return
+	}
+
+	if f.Remove(name) {
+		if err := f.WriteFile(repositoryConfig, 0o600); err != nil {
+			resp.Diagnostics.AddError(
+				"Error writing repository file",
+				fmt.Sprintf("Unable to remove repository %q from %s: %s", name, repositoryConfig, err),
+			)
+			return
+		}
+	}
+
+	// Remove the cached index and charts list, as `helm repo remove` does
+	for _, file := range []string{helmpath.CacheIndexFile(name), helmpath.CacheChartsFile(name)} {
+		cacheFile := filepath.Join(meta.Settings.RepositoryCache, file)
+		if err := os.Remove(cacheFile); err != nil && !os.IsNotExist(err) {
+			resp.Diagnostics.AddWarning(
+				"Error removing repository cache",
+				fmt.Sprintf("Unable to remove %s: %s", cacheFile, err),
+			)
+		}
+	}
+}
+
+func (r *HelmRepository) ImportState(ctx context.Context, req resource.ImportStateRequest, resp *resource.ImportStateResponse) {
+	resource.ImportStatePassthroughID(ctx, path.Root("name"), req, resp)
+	resp.Diagnostics.Append(resp.State.SetAttribute(ctx, path.Root("id"), req.ID)...)
+}
+
+// addRepository downloads the index of the repository into the repository
+// cache and adds the repository to the repository file. An existing entry with
+// the same name is only replaced when overwrite is set.
+func (r *HelmRepository) addRepository(ctx context.Context, model *HelmRepositoryModel, overwrite bool) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	meta := r.meta
+	if meta == nil {
+		diags.AddError(
+			"Meta not set",
+			"The meta information is not set for the resource",
+		)
+		return diags
+	}
+
+	name := model.Name.ValueString()
+	if registry.IsOCI(model.URL.ValueString()) {
+		diags.AddAttributeError(
+			path.Root("url"),
+			"Invalid Repository URL",
+			fmt.Sprintf("Repository %q is an OCI registry, OCI charts can be used from helm_release without adding a repository", name),
+		)
+		return diags
+	}
+
+	entry := &repo.Entry{
+		Name:                  name,
+		URL:                   model.URL.ValueString(),
+		Username:              model.Username.ValueString(),
+		Password:              model.Password.ValueString(),
+		CAFile:                model.CaFile.ValueString(),
+		CertFile:              model.CertFile.ValueString(),
+		KeyFile:               model.KeyFile.ValueString(),
+		InsecureSkipTLSverify: model.InsecureSkipTLSVerify.ValueBool(),
+		PassCredentialsAll:    model.PassCredentials.ValueBool(),
+	}
+
+	repositoryConfig := meta.Settings.RepositoryConfig
+
+	meta.Mutex.Lock()
+	defer meta.Mutex.Unlock()
+
+	unlock, err := lockRepositoryFile(ctx, repositoryConfig)
+	if err != nil {
+		diags.AddError(
+			"Error locking repository file",
+			fmt.Sprintf("Unable to lock repository file %s: %s", repositoryConfig, err),
+		)
+		return diags
+	}
+	defer unlock()
+
+	f, err := loadRepositoryFile(repositoryConfig)
+	if err != nil {
+		diags.AddError(
+			"Error reading repository file",
+			fmt.Sprintf("Unable to read repository file %s: %s", repositoryConfig, err),
+		)
+		return diags
+	}
+
+	if existing := f.Get(name); existing != nil && !overwrite && *existing != *entry {
+		diags.AddError(
+			"Repository already exists",
+			fmt.Sprintf("Repository %q already exists in %s with a different configuration, import it to manage it with Terraform", name, repositoryConfig),
+		)
+		return diags
+	}
+
+	cr, err := repo.NewChartRepository(entry, getter.All(meta.Settings))
+	if err != nil {
+		diags.AddError(
+			"Error creating chart repository",
+			fmt.Sprintf("Unable to create chart repository %q: %s", name, err),
+		)
+		return diags
+	}
+	cr.CachePath = meta.Settings.RepositoryCache
+
+	tflog.Debug(ctx, fmt.Sprintf("Downloading the index of repository %q from %s", name, entry.URL))
+	indexFile, err := cr.DownloadIndexFile()
+	if err != nil {
+		diags.AddError(
+			"Error downloading repository index",
+			fmt.Sprintf("Looks like %q is not a valid chart repository or cannot be reached: %s", entry.URL, err),
+		)
+		return diags
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Index of repository %q downloaded to %s", name, indexFile))
+
+	f.Update(entry)
+	if err := f.WriteFile(repositoryConfig, 0o600); err != nil {
+		diags.AddError(
+			"Error writing repository file",
+			fmt.Sprintf("Unable to add repository %q to %s: %s", name, repositoryConfig, err),
+		)
+		return diags
+	}
+
+	return diags
+}
+
+// lockRepositoryFile takes the file lock `helm repo add` takes on the
+// repository file, so that concurrent Terraform runs and the helm CLI don't
+// lose each other's changes. The returned function releases the lock.
+func lockRepositoryFile(ctx context.Context, repositoryConfig string) (func(), error) {
+	// The directory of the repository file must exist to create the lock file
+	if err := os.MkdirAll(filepath.Dir(repositoryConfig), 0o755); err != nil {
+		return nil, err
+	}
+
+	lockPath := repositoryConfig + ".lock"
+	if ext := filepath.Ext(repositoryConfig); ext != "" && len(ext) < len(repositoryConfig) {
+		lockPath = strings.TrimSuffix(repositoryConfig, ext) + ".lock"
+	}
+	fileLock := flock.New(lockPath)
+
+	lockCtx, cancel := context.WithTimeout(ctx, repositoryLockTimeout)
+	defer cancel()
+	locked, err := fileLock.TryLockContext(lockCtx, time.Second)
+	if err != nil {
+		return nil, err
+	}
+	if !locked {
+		return nil, fmt.Errorf("%s is locked by another process", lockPath)
+	}
+	return func() { _ = fileLock.Unlock() }, nil
+}
+
+// loadRepositoryFile loads the repository file at path, or returns an empty
+// one if it does not exist yet
+func loadRepositoryFile(path string) (*repo.File, error) {
+	f, err := repo.LoadFile(path)
+	if err != nil {
+		if os.IsNotExist(errors.Cause(err)) {
+			return repo.NewFile(), nil
+		}
+		return nil, err
+	}
+	return f, nil
+}
+
+// optionalStringValue returns the value read for an optional attribute,
+// keeping it null when it was not set and nothing was read
+func optionalStringValue(current types.String, value string) types.String {
+	if value == "" && current.IsNull() {
+		return current
+	}
+	return types.StringValue(value)
+}
@@ -0,0 +1,163 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gofrs/flock"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	"helm.sh/helm/v3/pkg/cli"
+	"helm.sh/helm/v3/pkg/helmpath"
+	"helm.sh/helm/v3/pkg/repo"
+)
+
+func TestHelmRepository_addRepository(t *testing.T) {
+	ctx := context.Background()
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/index.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "apiVersion: v1\nentries: {}\n")
+	}))
+	defer server.Close()
+
+	dir := t.TempDir()
+	settings := cli.New()
+	settings.RepositoryConfig = filepath.Join(dir, "config", "repositories.yaml")
+	settings.RepositoryCache = filepath.Join(dir, "cache")
+	r := &HelmRepository{meta: &Meta{Settings: settings}}
+
+	model := &HelmRepositoryModel{
+		Name:                  types.StringValue("test"),
+		URL:                   types.StringValue(server.URL),
+		Username:              types.StringNull(),
+		Password:              types.StringNull(),
+		CaFile:                types.StringNull(),
+		CertFile:              types.StringNull(),
+		KeyFile:               types.StringNull(),
+		InsecureSkipTLSVerify: types.BoolValue(false),
+		PassCredentials:       types.BoolValue(false),
+	}
+	if diags := r.addRepository(ctx, model, false); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	f, err := repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entry := f.Get("test"); entry == nil || entry.URL != server.URL {
+		t.Fatalf("expected repository %q to be added to the repository file, got %+v", "test", f.Repositories)
+	}
+	if _, err := os.Stat(filepath.Join(settings.RepositoryCache, helmpath.CacheIndexFile("test"))); err != nil {
+		t.Errorf("expected the index to be downloaded to the repository cache: %s", err)
+	}
+
+	// Adding the same repository again is a no-op, changing it requires an update
+	if diags := r.addRepository(ctx, model, false); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	model.PassCredentials = types.BoolValue(true)
+	if diags := r.addRepository(ctx, model, false); !diags.HasError() {
+		t.Fatal("expected an error when adding an existing repository with a different configuration")
+	}
+	if diags := r.addRepository(ctx, model, true); diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+
+	model.Name = types.StringValue("invalid")
+	model.URL = types.StringValue(server.URL + "/invalid")
+	if diags := r.addRepository(ctx, model, false); !diags.HasError() {
+		t.Fatal("expected an error for a repository without an index")
+	}
+	f, err = repo.LoadFile(settings.RepositoryConfig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if f.Has("invalid") {
+		t.Error("expected an invalid repository not to be added to the repository file")
+	}
+
+	// The repository file is not changed while the helm CLI holds its lock
+	fileLock := flock.New(filepath.Join(dir, "config", "repositories.lock"))
+	if err := fileLock.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	defer fileLock.Unlock()
+	lockCtx, cancel := context.WithTimeout(ctx, 100*time.Millisecond)
+	defer cancel()
+	model.Name = types.StringValue("locked")
+	model.URL = types.StringValue(server.URL)
+	if diags := r.addRepository(lockCtx, model, false); !diags.HasError() || diags.Errors()[0].Summary() != "Error locking repository file" {
+		t.Fatalf("expected an error while the repository file is locked, got %v", diags)
+	}
+}
+
+func TestAccResourceRepository_basic(t *testing.T) {
+	name := randName("repository")
+	namespace := randName(testNamespacePrefix)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy:             testAccCheckHelmRepositoryDestroy(name),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmRepositoryConfigBasic(testResourceName, name, namespace),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_repository.test", "id", name),
+					resource.TestCheckResourceAttr("helm_repository.test", "url", testRepositoryURL),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.chart", "test-chart"),
+					resource.TestCheckResourceAttr("helm_release.test", "status", "deployed"),
+				),
+			},
+			{
+				ResourceName:      "helm_repository.test",
+				ImportState:       true,
+				ImportStateId:     name,
+				ImportStateVerify: true,
+			},
+		},
+	})
+}
+
+func testAccCheckHelmRepositoryDestroy(name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		f, err := repo.LoadFile(os.Getenv("HELM_REPOSITORY_CONFIG"))
+		if err != nil {
+			return nil
+		}
+		if f.Has(name) {
+			return fmt.Errorf("repository %q still exists in the repository file", name)
+		}
+		return nil
+	}
+}
+
+func testAccHelmRepositoryConfigBasic(resource, name, ns string) string {
+	return fmt.Sprintf(`
+		resource "helm_repository" "%[1]s" {
+			name = %[2]q
+			url  = %[4]q
+		}
+
+		resource "helm_release" "%[1]s" {
+			name      = %[2]q
+			namespace = %[3]q
+			chart     = "${helm_repository.%[1]s.name}/test-chart"
+			version   = "1.2.3"
+		}
+	`, resource, name, ns, testRepositoryURL)
+}
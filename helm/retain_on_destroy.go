@@ -0,0 +1,292 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	pathpkg "path"
+	"sort"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	"helm.sh/helm/v3/pkg/releaseutil"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/labels"
+	k8stypes "k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/yaml"
+)
+
+// RetainOnDestroyModel selects objects of a release that are kept when the
+// release is destroyed
+type RetainOnDestroyModel struct {
+	Kind          types.String `tfsdk:"kind"`
+	LabelSelector types.String `tfsdk:"label_selector"`
+	Name          types.String `tfsdk:"name"`
+}
+
+func retainOnDestroyAttrTypes() map[string]attr.Type {
+	return map[string]attr.Type{
+		"kind":           types.StringType,
+		"label_selector": types.StringType,
+		"name":           types.StringType,
+	}
+}
+
+// matches returns whether a selector selects an object of the given kind,
+// name and labels
+func (m RetainOnDestroyModel) matches(kind, name string, objectLabels map[string]string) bool {
+	if !strings.EqualFold(m.Kind.ValueString(), kind) {
+		return false
+	}
+	if pattern := m.Name.ValueString(); pattern != "" {
+		if ok, _ := pathpkg.Match(pattern, name); !ok {
+			return false
+		}
+	}
+	if s := m.LabelSelector.ValueString(); s != "" {
+		selector, err := labels.Parse(s)
+		if err != nil || !selector.Matches(labels.Set(objectLabels)) {
+			return false
+		}
+	}
+	return true
+}
+
+// validateRetainOnDestroy checks that the name globs and the label selectors
+// of retain_on_destroy are valid when planning.
+func validateRetainOnDestroy(ctx context.Context, retain types.List) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if retain.IsNull() || retain.IsUnknown() {
+		return diags
+	}
+
+	var models []RetainOnDestroyModel
+	diags.Append(retain.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+	for i, model := range models {
+		if !model.Name.IsUnknown() {
+			if _, err := pathpkg.Match(model.Name.ValueString(), ""); err != nil {
+				diags.AddAttributeError(
+					path.Root("retain_on_destroy").AtListIndex(i).AtName("name"),
+					"Invalid name pattern",
+					fmt.Sprintf("Unable to parse the name pattern %q: %s", model.Name.ValueString(), err),
+				)
+			}
+		}
+		if !model.LabelSelector.IsUnknown() {
+			if _, err := labels.Parse(model.LabelSelector.ValueString()); err != nil {
+				diags.AddAttributeError(
+					path.Root("retain_on_destroy").AtListIndex(i).AtName("label_selector"),
+					"Invalid label selector",
+					fmt.Sprintf("Unable to parse the label selector %q: %s", model.LabelSelector.ValueString(), err),
+				)
+			}
+		}
+	}
+	return diags
+}
+
+// retainReleaseObjects marks the live objects of a release selected by
+// retain_on_destroy with the keep resource policy of Helm, so that they
+// outlive the uninstallation of the release. As Helm reads the resource
+// policy from the manifest of the release rather than from the live objects,
+// the annotation is also added to the manifest of the last release record.
+// The retained objects are listed in a warning.
+func retainReleaseObjects(ctx context.Context, actionConfig *action.Configuration, state *HelmReleaseModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	name := state.Name.ValueString()
+
+	var models []RetainOnDestroyModel
+	diags.Append(state.RetainOnDestroy.ElementsAs(ctx, &models, false)...)
+	if diags.HasError() {
+		return diags
+	}
+
+	rel, err := actionConfig.Releases.Last(name)
+	if err != nil {
+		diags.AddError("Error retaining objects", fmt.Sprintf("Unable to get the last revision of release %q: %s", name, err))
+		return diags
+	}
+	resources, err := buildReleaseResources(actionConfig, rel)
+	if err != nil {
+		diags.AddError("Error retaining objects", fmt.Sprintf("Unable to build the objects of release %q: %s", name, err))
+		return diags
+	}
+	kc, err := getKubeClient(actionConfig)
+	if err != nil {
+		diags.AddError("Error retaining objects", fmt.Sprintf("Unable to get the kubernetes client of release %q: %s", name, err))
+		return diags
+	}
+	dynamicClient, err := kc.Factory.DynamicClient()
+	if err != nil {
+		diags.AddError("Error retaining objects", fmt.Sprintf("Unable to get the kubernetes client of release %q: %s", name, err))
+		return diags
+	}
+
+	patch := []byte(fmt.Sprintf(`{"metadata":{"annotations":{%q:%q}}}`, kube.ResourcePolicyAnno, kube.KeepPolicy))
+	var retained []retainedObject
+	for _, info := range resources {
+		kind := info.Mapping.GroupVersionKind.Kind
+		selected := false
+		for _, model := range models {
+			if strings.EqualFold(model.Kind.ValueString(), kind) {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			continue
+		}
+
+		client := dynamicClient.Resource(info.Mapping.Resource)
+		object := retainedObject{kind: kind, name: info.Name}
+		var live *unstructured.Unstructured
+		if info.Mapping.Scope.Name() == apimeta.RESTScopeNameNamespace {
+			object.namespace = info.Namespace
+			live, err = client.Namespace(object.namespace).Get(ctx, object.name, metav1.GetOptions{})
+		} else {
+			live, err = client.Get(ctx, object.name, metav1.GetOptions{})
+		}
+		if apierrors.IsNotFound(err) {
+			continue
+		}
+		if err != nil {
+			diags.AddError("Error retaining objects", fmt.Sprintf("Unable to get %s of release %q: %s", object, name, err))
+			return diags
+		}
+
+		selected = false
+		for _, model := range models {
+			if model.matches(kind, object.name, live.GetLabels()) {
+				selected = true
+				break
+			}
+		}
+		if !selected {
+			continue
+		}
+
+		if object.namespace != "" {
+			_, err = client.Namespace(object.namespace).Patch(ctx, object.name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+		} else {
+			_, err = client.Patch(ctx, object.name, k8stypes.MergePatchType, patch, metav1.PatchOptions{})
+		}
+		if err != nil {
+			diags.AddError("Error retaining objects", fmt.Sprintf("Unable to annotate %s of release %q: %s", object, name, err))
+			return diags
+		}
+		tflog.Debug(ctx, fmt.Sprintf("Annotated %s of release %s with the keep resource policy", object, name))
+		retained = append(retained, object)
+	}
+	if len(retained) == 0 {
+		return diags
+	}
+
+	manifest, err := keepManifestObjects(rel.Manifest, rel.Namespace, retained)
+	if err != nil {
+		diags.AddError("Error retaining objects", fmt.Sprintf("Unable to update the manifest of release %q: %s", name, err))
+		return diags
+	}
+	rel.Manifest = manifest
+	if err := actionConfig.Releases.Update(rel); err != nil {
+		diags.AddError("Error retaining objects", fmt.Sprintf("Unable to update release %q: %s", name, err))
+		return diags
+	}
+
+	objects := make([]string, 0, len(retained))
+	for _, object := range retained {
+		objects = append(objects, object.String())
+	}
+	sort.Strings(objects)
+	diags.AddWarning(
+		"Helm release objects retained",
+		fmt.Sprintf("The following objects of release %q match retain_on_destroy. They were annotated with %s: %s and are left in the cluster after the release is uninstalled:\n  %s",
+			name, kube.ResourcePolicyAnno, kube.KeepPolicy, strings.Join(objects, "\n  ")),
+	)
+	return diags
+}
+
+// retainedObject is an object of a release selected by retain_on_destroy.
+// The namespace of cluster-scoped objects is empty.
+type retainedObject struct {
+	kind      string
+	namespace string
+	name      string
+}
+
+func (o retainedObject) String() string {
+	if o.namespace == "" {
+		return fmt.Sprintf("%s %s", o.kind, o.name)
+	}
+	return fmt.Sprintf("%s %s/%s", o.kind, o.namespace, o.name)
+}
+
+// keepManifestObjects adds the keep resource policy annotation to the
+// retained objects of a manifest. Objects without a namespace in the
+// manifest are in the namespace of the release, unless they are
+// cluster-scoped. The other documents are left untouched.
+func keepManifestObjects(manifest, releaseNamespace string, retained []retainedObject) (string, error) {
+	documents := releaseutil.SplitManifests(manifest)
+	keys := make([]string, 0, len(documents))
+	for key := range documents {
+		keys = append(keys, key)
+	}
+	sort.Sort(releaseutil.BySplitManifestsOrder(keys))
+
+	var b strings.Builder
+	for _, key := range keys {
+		document := documents[key]
+		var object map[string]interface{}
+		if err := yaml.Unmarshal([]byte(document), &object); err != nil {
+			return "", err
+		}
+		if object != nil && isRetainedManifestObject(object, releaseNamespace, retained) {
+			if err := unstructured.SetNestedField(object, kube.KeepPolicy, "metadata", "annotations", kube.ResourcePolicyAnno); err != nil {
+				return "", err
+			}
+			out, err := yaml.Marshal(object)
+			if err != nil {
+				return "", err
+			}
+			// Keep the comments heading the document, such as its source
+			var comments []string
+			for _, line := range strings.Split(document, "\n") {
+				if !strings.HasPrefix(line, "#") {
+					break
+				}
+				comments = append(comments, line)
+			}
+			document = strings.TrimSpace(strings.Join(append(comments, string(out)), "\n"))
+		}
+		fmt.Fprintf(&b, "---\n%s\n", document)
+	}
+	return b.String(), nil
+}
+
+func isRetainedManifestObject(object map[string]interface{}, releaseNamespace string, retained []retainedObject) bool {
+	kind, _ := object["kind"].(string)
+	name, _, _ := unstructured.NestedString(object, "metadata", "name")
+	namespace, _, _ := unstructured.NestedString(object, "metadata", "namespace")
+	for _, r := range retained {
+		if r.kind != kind || r.name != name {
+			continue
+		}
+		if r.namespace == namespace || (namespace == "" && (r.namespace == "" || r.namespace == releaseNamespace)) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,206 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"github.com/hashicorp/terraform-plugin-testing/terraform"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const testRetainManifest = `---
+# Source: app/templates/pvc.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-app
+spec:
+  accessModes: ["ReadWriteOnce"]
+---
+# Source: app/templates/crd.yaml
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  name: widgets.example.com
+  annotations:
+    owner: platform
+---
+# Source: app/templates/pvc-other.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-app
+  namespace: other
+`
+
+func TestKeepManifestObjects(t *testing.T) {
+	manifest, err := keepManifestObjects(testRetainManifest, "apps", []retainedObject{
+		{kind: "PersistentVolumeClaim", namespace: "apps", name: "data-app"},
+		{kind: "CustomResourceDefinition", name: "widgets.example.com"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `---
+# Source: app/templates/pvc.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  annotations:
+    helm.sh/resource-policy: keep
+  name: data-app
+spec:
+  accessModes:
+  - ReadWriteOnce
+---
+# Source: app/templates/crd.yaml
+apiVersion: apiextensions.k8s.io/v1
+kind: CustomResourceDefinition
+metadata:
+  annotations:
+    helm.sh/resource-policy: keep
+    owner: platform
+  name: widgets.example.com
+---
+# Source: app/templates/pvc-other.yaml
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: data-app
+  namespace: other
+`
+	if manifest != expected {
+		t.Errorf("unexpected manifest:\n%s\nexpected:\n%s", manifest, expected)
+	}
+}
+
+func TestRetainOnDestroyMatches(t *testing.T) {
+	selector := func(kind, name, labelSelector string) RetainOnDestroyModel {
+		m := RetainOnDestroyModel{Kind: types.StringValue(kind), Name: types.StringNull(), LabelSelector: types.StringNull()}
+		if name != "" {
+			m.Name = types.StringValue(name)
+		}
+		if labelSelector != "" {
+			m.LabelSelector = types.StringValue(labelSelector)
+		}
+		return m
+	}
+	labels := map[string]string{"app.kubernetes.io/component": "database"}
+
+	tests := map[string]struct {
+		selector RetainOnDestroyModel
+		kind     string
+		name     string
+		matches  bool
+	}{
+		"kind":                 {selector("persistentvolumeclaim", "", ""), "PersistentVolumeClaim", "data-app", true},
+		"other kind":           {selector("Secret", "", ""), "PersistentVolumeClaim", "data-app", false},
+		"name glob":            {selector("PersistentVolumeClaim", "data-*", ""), "PersistentVolumeClaim", "data-app", true},
+		"other name":           {selector("PersistentVolumeClaim", "cache-*", ""), "PersistentVolumeClaim", "data-app", false},
+		"label selector":       {selector("PersistentVolumeClaim", "", "app.kubernetes.io/component=database"), "PersistentVolumeClaim", "data-app", true},
+		"other label selector": {selector("PersistentVolumeClaim", "", "app.kubernetes.io/component in (cache)"), "PersistentVolumeClaim", "data-app", false},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			if matches := tt.selector.matches(tt.kind, tt.name, labels); matches != tt.matches {
+				t.Errorf("expected %t, got %t", tt.matches, matches)
+			}
+		})
+	}
+}
+
+func TestValidateRetainOnDestroy(t *testing.T) {
+	retain, diags := types.ListValueFrom(context.Background(), types.ObjectType{AttrTypes: retainOnDestroyAttrTypes()}, []RetainOnDestroyModel{
+		{Kind: types.StringValue("PersistentVolumeClaim"), Name: types.StringValue("data-*"), LabelSelector: types.StringNull()},
+		{Kind: types.StringValue("Secret"), Name: types.StringValue("data-[a"), LabelSelector: types.StringValue("app in (")},
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	diags = validateRetainOnDestroy(context.Background(), retain)
+	if diags.ErrorsCount() != 2 {
+		t.Fatalf("expected two errors, got %v", diags)
+	}
+	for i, attribute := range []string{"name", "label_selector"} {
+		withPath, ok := diags[i].(diag.DiagnosticWithPath)
+		if !ok || !withPath.Path().Equal(path.Root("retain_on_destroy").AtListIndex(1).AtName(attribute)) {
+			t.Errorf("expected the error to be attributed to retain_on_destroy[1].%s, got %v", attribute, diags[i])
+		}
+	}
+}
+
+func TestAccResourceRelease_retainOnDestroy(t *testing.T) {
+	name := randName("retain")
+	namespace := createRandomNamespace(t)
+	defer deleteNamespace(t, namespace)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		CheckDestroy:             testAccCheckHelmReleaseRetained(namespace, fmt.Sprintf("%s-test-chart", name)),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccHelmReleaseConfigRetainOnDestroy(testResourceName, namespace, name, ""),
+				Check:  resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+			},
+			{
+				// Adding retain_on_destroy doesn't upgrade the release
+				Config: testAccHelmReleaseConfigRetainOnDestroy(testResourceName, namespace, name, `
+					retain_on_destroy = [
+						{
+							kind = "Service"
+							name = "*-test-chart"
+						},
+					]
+				`),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr("helm_release.test", "retain_on_destroy.#", "1"),
+					resource.TestCheckResourceAttr("helm_release.test", "metadata.revision", "1"),
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigRetainOnDestroy(resource, ns, name, retain string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name       = %q
+			namespace  = %q
+			repository = %q
+			chart      = "test-chart"
+			version    = "1.2.3"
+
+			%s
+		}
+	`, resource, name, ns, testRepositoryURL, retain)
+}
+
+// testAccCheckHelmReleaseRetained checks that a Service of a destroyed
+// release was kept, with the keep resource policy, while its Deployment was
+// deleted
+func testAccCheckHelmReleaseRetained(namespace, name string) resource.TestCheckFunc {
+	return func(s *terraform.State) error {
+		ctx := context.Background()
+		service, err := client.CoreV1().Services(namespace).Get(ctx, name, metav1.GetOptions{})
+		if err != nil {
+			return fmt.Errorf("expected Service %s/%s to be retained: %w", namespace, name, err)
+		}
+		if policy := service.Annotations["helm.sh/resource-policy"]; policy != "keep" {
+			return fmt.Errorf("expected Service %s/%s to have the keep resource policy, got %q", namespace, name, policy)
+		}
+		if _, err := client.AppsV1().Deployments(namespace).Get(ctx, name, metav1.GetOptions{}); err == nil {
+			return fmt.Errorf("expected Deployment %s/%s to be deleted", namespace, name)
+		}
+		return nil
+	}
+}
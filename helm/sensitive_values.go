@@ -0,0 +1,58 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"strings"
+)
+
+// setSensitiveValues returns the values of the set_sensitive attribute of a
+// release, which are redacted from the drift summary, the outputs and the
+// failure reports of the release.
+func setSensitiveValues(ctx context.Context, state *HelmReleaseModel) []string {
+	var values []string
+	if state.SetSensitive.IsNull() || state.SetSensitive.IsUnknown() {
+		return values
+	}
+	var setSensitiveList []setResourceModel
+	if diags := state.SetSensitive.ElementsAs(ctx, &setSensitiveList, false); diags.HasError() {
+		return values
+	}
+	for _, set := range setSensitiveList {
+		if v := set.Value.ValueString(); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
+
+// redactSetSensitiveValues replaces a value that contains one of the values of
+// set_sensitive with a placeholder
+func redactSetSensitiveValues(value string, sensitiveValues []string) string {
+	for _, v := range sensitiveValues {
+		if strings.Contains(value, v) {
+			return sensitiveContentValue
+		}
+	}
+	return value
+}
+
+// setWOValues returns the values of the set_wo attribute of a configuration
+func setWOValues(ctx context.Context, model *HelmReleaseModel) []string {
+	var values []string
+	if model.SetWO.IsNull() || model.SetWO.IsUnknown() {
+		return values
+	}
+	var setWOList []setResourceModel
+	if diags := model.SetWO.ElementsAs(ctx, &setWOList, false); diags.HasError() {
+		return values
+	}
+	for _, set := range setWOList {
+		if v := set.Value.ValueString(); v != "" {
+			values = append(values, v)
+		}
+	}
+	return values
+}
@@ -0,0 +1,155 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"github.com/pkg/errors"
+	"helm.sh/helm/v3/pkg/action"
+	"helm.sh/helm/v3/pkg/kube"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	apimeta "k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	apitypes "k8s.io/apimachinery/pkg/types"
+	"k8s.io/cli-runtime/pkg/resource"
+)
+
+// defaultFieldManager is the field manager of the objects applied with
+// server-side apply when none is configured.
+const defaultFieldManager = "terraform-provider-helm"
+
+// useServerSideApply makes the install, upgrade and rollback actions run
+// with actionConfig apply the rendered objects with server-side apply. The
+// releases are still recorded in the Helm storage as usual.
+func useServerSideApply(ctx context.Context, actionConfig *action.Configuration, model *ServerSideApplyModel) diag.Diagnostics {
+	var diags diag.Diagnostics
+	if model == nil {
+		return diags
+	}
+
+	kc, err := getKubeClient(actionConfig)
+	if err != nil {
+		diags.AddError("Kube Client Error", err.Error())
+		return diags
+	}
+
+	fieldManager := model.FieldManager.ValueString()
+	if fieldManager == "" {
+		fieldManager = defaultFieldManager
+	}
+	tflog.Debug(ctx, fmt.Sprintf("Using server-side apply with field manager %q (force conflicts: %t)", fieldManager, model.ForceConflicts.ValueBool()))
+	actionConfig.KubeClient = &serverSideApplyClient{
+		Client:         kc,
+		fieldManager:   fieldManager,
+		forceConflicts: model.ForceConflicts.ValueBool(),
+	}
+	return diags
+}
+
+// serverSideApplyClient is a Helm kube client creating and updating objects
+// with server-side apply instead of Helm's client-side three-way merge.
+// Everything else, waiting and deleting included, is left to kube.Client.
+type serverSideApplyClient struct {
+	*kube.Client
+	fieldManager   string
+	forceConflicts bool
+}
+
+// Create implements kube.Interface
+func (c *serverSideApplyClient) Create(resources kube.ResourceList) (*kube.Result, error) {
+	res := &kube.Result{}
+	err := resources.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+		res.Created = append(res.Created, info)
+		return c.apply(info)
+	})
+	return res, err
+}
+
+// Update implements kube.Interface. As kube.Client.Update, it applies the
+// target objects, then deletes the original objects that are not part of the
+// target anymore. force is ignored: conflicts are handled by forceConflicts.
+func (c *serverSideApplyClient) Update(original, target kube.ResourceList, force bool) (*kube.Result, error) {
+	res := &kube.Result{}
+
+	c.Log("applying %d resources with field manager %q", len(target), c.fieldManager)
+	err := target.Visit(func(info *resource.Info, err error) error {
+		if err != nil {
+			return err
+		}
+
+		helper := resource.NewHelper(info.Client, info.Mapping)
+		if _, err := helper.Get(info.Namespace, info.Name); err != nil {
+			if !apierrors.IsNotFound(err) {
+				return errors.Wrap(err, "could not get information about the resource")
+			}
+			res.Created = append(res.Created, info)
+			return c.apply(info)
+		}
+
+		if original.Get(info) == nil {
+			kind := info.Mapping.GroupVersionKind.Kind
+			return errors.Errorf("no %s with the name %q found", kind, info.Name)
+		}
+		res.Updated = append(res.Updated, info)
+		return c.apply(info)
+	})
+	if err != nil {
+		return res, err
+	}
+
+	for _, info := range original.Difference(target) {
+		c.Log("Deleting %s %q in namespace %s...", info.Mapping.GroupVersionKind.Kind, info.Name, info.Namespace)
+
+		if err := info.Get(); err != nil {
+			c.Log("Unable to get obj %q, err: %s", info.Name, err)
+			continue
+		}
+		annotations, err := apimeta.NewAccessor().Annotations(info.Object)
+		if err != nil {
+			c.Log("Unable to get annotations on %q, err: %s", info.Name, err)
+		}
+		if annotations != nil && annotations[kube.ResourcePolicyAnno] == kube.KeepPolicy {
+			c.Log("Skipping delete of %q due to annotation [%s=%s]", info.Name, kube.ResourcePolicyAnno, kube.KeepPolicy)
+			continue
+		}
+		policy := metav1.DeletePropagationBackground
+		_, err = resource.NewHelper(info.Client, info.Mapping).DeleteWithOptions(info.Namespace, info.Name, &metav1.DeleteOptions{PropagationPolicy: &policy})
+		if err != nil && !apierrors.IsNotFound(err) {
+			c.Log("Failed to delete %q, err: %s", info.ObjectName(), err)
+			continue
+		}
+		res.Deleted = append(res.Deleted, info)
+	}
+	return res, nil
+}
+
+// apply applies an object with server-side apply and refreshes info with the
+// object returned by the API server.
+func (c *serverSideApplyClient) apply(info *resource.Info) error {
+	data, err := runtime.Encode(unstructured.UnstructuredJSONScheme, info.Object)
+	if err != nil {
+		return errors.Wrapf(err, "unable to encode %s %q", info.Mapping.GroupVersionKind.Kind, info.Name)
+	}
+
+	force := c.forceConflicts
+	obj, err := resource.NewHelper(info.Client, info.Mapping).
+		WithFieldManager(c.fieldManager).
+		Patch(info.Namespace, info.Name, apitypes.ApplyPatchType, data, &metav1.PatchOptions{Force: &force})
+	if err != nil {
+		if apierrors.IsConflict(err) {
+			return errors.Wrapf(err, "conflicts applying %s %q, set force_conflicts to take the ownership of the conflicting fields", info.Mapping.GroupVersionKind.Kind, info.Name)
+		}
+		return errors.Wrapf(err, "cannot apply %s %q", info.Mapping.GroupVersionKind.Kind, info.Name)
+	}
+	return info.Refresh(obj, true)
+}
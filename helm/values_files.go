@@ -0,0 +1,116 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	pathpkg "path"
+	"path/filepath"
+
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-log/tflog"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/getter"
+)
+
+// valuesFile is the content of an element of the values_files attribute
+type valuesFile struct {
+	index   int
+	name    string
+	content []byte
+}
+
+// readValuesFiles reads the elements of the values_files attribute
+func readValuesFiles(ctx context.Context, meta *Meta, c *chart.Chart, files types.List) ([]valuesFile, diag.Diagnostics) {
+	var diags diag.Diagnostics
+	var valuesFiles []valuesFile
+
+	for i, raw := range files.Elements() {
+		name, ok := raw.(types.String)
+		if !ok || name.IsNull() || name.IsUnknown() {
+			continue
+		}
+
+		tflog.Debug(ctx, fmt.Sprintf("Reading values file %s", name.ValueString()))
+		content, err := readValuesFile(meta, c, name.ValueString())
+		if err != nil {
+			diags.AddAttributeError(
+				path.Root("values_files").AtListIndex(i),
+				"Error reading values file",
+				fmt.Sprintf("Unable to read values file %q: %s", name.ValueString(), err),
+			)
+			return nil, diags
+		}
+		valuesFiles = append(valuesFiles, valuesFile{index: i, name: name.ValueString(), content: content})
+	}
+	return valuesFiles, diags
+}
+
+// readValuesFile reads a values file the way helm reads the files given with
+// -f: URLs are fetched with the getter registered for their scheme, and other
+// names are read from the local filesystem. Names that don't exist locally are
+// looked up in the files of the chart.
+func readValuesFile(meta *Meta, c *chart.Chart, name string) ([]byte, error) {
+	// A single letter scheme is a Windows drive letter
+	if u, err := url.Parse(name); err == nil && len(u.Scheme) > 1 {
+		g, err := getter.All(meta.Settings).ByScheme(u.Scheme)
+		if err != nil {
+			return nil, err
+		}
+		data, err := g.Get(name, getter.WithURL(name))
+		if err != nil {
+			return nil, err
+		}
+		return data.Bytes(), nil
+	}
+
+	content, err := os.ReadFile(name)
+	if err == nil || !os.IsNotExist(err) || c == nil {
+		return content, err
+	}
+
+	chartPath := pathpkg.Clean(filepath.ToSlash(name))
+	for _, f := range c.Files {
+		if f.Name == chartPath {
+			return f.Data, nil
+		}
+	}
+	return nil, fmt.Errorf("%s does not exist locally or in chart %s", name, c.Name())
+}
+
+// valuesFilesSHA256 returns the SHA256 checksums of the content of the values
+// files, by name
+func valuesFilesSHA256(ctx context.Context, files types.List, valuesFiles []valuesFile) (types.Map, diag.Diagnostics) {
+	if files.IsNull() {
+		return types.MapNull(types.StringType), nil
+	}
+
+	checksums := make(map[string]string, len(valuesFiles))
+	for _, f := range valuesFiles {
+		sum := sha256.Sum256(f.content)
+		checksums[f.name] = hex.EncodeToString(sum[:])
+	}
+	return types.MapValueFrom(ctx, types.StringType, checksums)
+}
+
+// valuesFilesUnknown returns true if values_files or any of its elements are
+// unknown
+func valuesFilesUnknown(files types.List) bool {
+	if files.IsUnknown() {
+		return true
+	}
+	for _, f := range files.Elements() {
+		if f.IsUnknown() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,147 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"helm.sh/helm/v3/pkg/chart/loader"
+	"helm.sh/helm/v3/pkg/cli"
+)
+
+func TestReadValuesFiles(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := loader.Load(filepath.Join(testChartsPath, "schema-chart"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	local := filepath.Join(t.TempDir(), "values-local.yaml")
+	if err := os.WriteFile(local, []byte("image:\n  tag: local\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/values-remote.yaml" {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, "image:\n  tag: remote\n  repository: example/nginx\n")
+	}))
+	defer server.Close()
+	remote := server.URL + "/values-remote.yaml"
+
+	meta := &Meta{Settings: cli.New()}
+	files := types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue("values-prod.yaml"),
+		types.StringValue(local),
+		types.StringValue(remote),
+	})
+
+	valuesFiles, diags := readValuesFiles(ctx, meta, c, files)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	if len(valuesFiles) != 3 {
+		t.Fatalf("expected 3 values files, got %d", len(valuesFiles))
+	}
+
+	model := &HelmReleaseModel{
+		Values:       types.ListValueMust(types.StringType, []attr.Value{types.StringValue("replicaCount: 5\n")}),
+		Set:          types.ListNull(types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "type": types.StringType, "value": types.StringType}}),
+		SetList:      types.ListNull(types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "value": types.ListType{ElemType: types.StringType}}}),
+		SetSensitive: types.ListNull(types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "type": types.StringType, "value": types.StringType}}),
+	}
+	values, diags := getValues(ctx, model, valuesFiles)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	expected := map[string]interface{}{
+		"replicaCount": float64(5),
+		"image": map[string]interface{}{
+			"pullPolicy": "Always",
+			"repository": "example/nginx",
+			"tag":        "remote",
+		},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values:\n%#v\nexpected:\n%#v", values, expected)
+	}
+
+	checksums, diags := valuesFilesSHA256(ctx, files, valuesFiles)
+	if diags.HasError() {
+		t.Fatalf("unexpected diagnostics: %v", diags)
+	}
+	sum := sha256.Sum256([]byte("image:\n  tag: local\n"))
+	if checksum := checksums.Elements()[local]; !checksum.Equal(types.StringValue(hex.EncodeToString(sum[:]))) {
+		t.Errorf("unexpected checksum for %s: %s", local, checksum)
+	}
+	if len(checksums.Elements()) != 3 {
+		t.Errorf("expected 3 checksums, got %v", checksums)
+	}
+
+	files = types.ListValueMust(types.StringType, []attr.Value{
+		types.StringValue(local),
+		types.StringValue("values-missing.yaml"),
+	})
+	_, diags = readValuesFiles(ctx, meta, c, files)
+	if !diags.HasError() {
+		t.Fatal("expected an error for a missing values file")
+	}
+	if withPath, ok := diags[0].(diag.DiagnosticWithPath); !ok || !withPath.Path().Equal(path.Root("values_files").AtListIndex(1)) {
+		t.Errorf("expected the error to be attributed to values_files[1], got %v", diags[0])
+	}
+}
+
+func TestAccDataTemplate_valuesFiles(t *testing.T) {
+	name := randName("values-files")
+	namespace := randName(testNamespacePrefix)
+
+	datasourceAddress := fmt.Sprintf("data.helm_template.%s", testResourceName)
+	sum := sha256.Sum256([]byte("replicaCount: 3\n\nimage:\n  pullPolicy: Always\n"))
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config: testAccDataHelmTemplateConfigValuesFiles(testResourceName, namespace, name, "values-prod.yaml"),
+				Check: resource.ComposeAggregateTestCheckFunc(
+					resource.TestCheckResourceAttr(datasourceAddress, "values_files_sha256.values-prod.yaml", hex.EncodeToString(sum[:])),
+					resource.TestMatchResourceAttr(datasourceAddress, "manifest", regexp.MustCompile(`replicaCount: "3"`)),
+				),
+			},
+			{
+				Config:      testAccDataHelmTemplateConfigValuesFiles(testResourceName, namespace, name, "values-missing.yaml"),
+				ExpectError: regexp.MustCompile(`Unable to read values file "values-missing.yaml"`),
+			},
+		},
+	})
+}
+
+func testAccDataHelmTemplateConfigValuesFiles(resource, ns, name, valuesFile string) string {
+	return fmt.Sprintf(`
+		data "helm_template" "%s" {
+			name         = %q
+			namespace    = %q
+			chart        = "%s/schema-chart"
+			values_files = [%q]
+		}
+	`, resource, name, ns, testChartsPath, valuesFile)
+}
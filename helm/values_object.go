@@ -0,0 +1,142 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"fmt"
+	"math/big"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+// getValuesObject returns the values of the values_object attribute
+func getValuesObject(v types.Dynamic) (map[string]interface{}, diag.Diagnostics) {
+	var diags diag.Diagnostics
+
+	if v.IsNull() || v.IsUnderlyingValueNull() {
+		return nil, diags
+	}
+
+	values, err := dynamicValueToInterface(v)
+	if err != nil {
+		diags.AddAttributeError(path.Root("values_object"), "Invalid values_object", err.Error())
+		return nil, diags
+	}
+
+	m, ok := values.(map[string]interface{})
+	if !ok {
+		diags.AddAttributeError(
+			path.Root("values_object"),
+			"Invalid values_object",
+			fmt.Sprintf("values_object must be an object or a map, got %s", v.UnderlyingValue().Type(nil)),
+		)
+		return nil, diags
+	}
+	return m, diags
+}
+
+// dynamicValueToInterface converts a value of a dynamic attribute to its
+// equivalent in the values of a chart. Whole numbers are converted to
+// integers, the other numbers to floats.
+func dynamicValueToInterface(v attr.Value) (interface{}, error) {
+	if v.IsNull() {
+		return nil, nil
+	}
+	if v.IsUnknown() {
+		return nil, fmt.Errorf("value is not known yet")
+	}
+
+	switch value := v.(type) {
+	case types.Dynamic:
+		return dynamicValueToInterface(value.UnderlyingValue())
+	case types.String:
+		return value.ValueString(), nil
+	case types.Bool:
+		return value.ValueBool(), nil
+	case types.Number:
+		f := value.ValueBigFloat()
+		if f.IsInt() {
+			if i, accuracy := f.Int64(); accuracy == big.Exact {
+				return i, nil
+			}
+		}
+		n, _ := f.Float64()
+		return n, nil
+	case types.Object:
+		return dynamicMapToInterface(value.Attributes())
+	case types.Map:
+		return dynamicMapToInterface(value.Elements())
+	case types.List:
+		return dynamicListToInterface(value.Elements())
+	case types.Tuple:
+		return dynamicListToInterface(value.Elements())
+	case types.Set:
+		return dynamicListToInterface(value.Elements())
+	default:
+		return nil, fmt.Errorf("unsupported value type %s", v.Type(nil))
+	}
+}
+
+func dynamicMapToInterface(elements map[string]attr.Value) (map[string]interface{}, error) {
+	m := make(map[string]interface{}, len(elements))
+	for k, e := range elements {
+		v, err := dynamicValueToInterface(e)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", k, err)
+		}
+		m[k] = v
+	}
+	return m, nil
+}
+
+func dynamicListToInterface(elements []attr.Value) ([]interface{}, error) {
+	l := make([]interface{}, len(elements))
+	for i, e := range elements {
+		v, err := dynamicValueToInterface(e)
+		if err != nil {
+			return nil, fmt.Errorf("[%d]: %w", i, err)
+		}
+		l[i] = v
+	}
+	return l, nil
+}
+
+// dynamicValueUnknown returns true if v or any of its nested values are unknown
+func dynamicValueUnknown(v attr.Value) bool {
+	if v.IsUnknown() {
+		return true
+	}
+	if v.IsNull() {
+		return false
+	}
+
+	var elements []attr.Value
+	switch value := v.(type) {
+	case types.Dynamic:
+		return dynamicValueUnknown(value.UnderlyingValue())
+	case types.Object:
+		for _, e := range value.Attributes() {
+			elements = append(elements, e)
+		}
+	case types.Map:
+		for _, e := range value.Elements() {
+			elements = append(elements, e)
+		}
+	case types.List:
+		elements = value.Elements()
+	case types.Tuple:
+		elements = value.Elements()
+	case types.Set:
+		elements = value.Elements()
+	}
+	for _, e := range elements {
+		if dynamicValueUnknown(e) {
+			return true
+		}
+	}
+	return false
+}
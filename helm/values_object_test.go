@@ -0,0 +1,103 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+)
+
+func TestGetValues_valuesObject(t *testing.T) {
+	ctx := context.Background()
+
+	setType := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name":  types.StringType,
+			"type":  types.StringType,
+			"value": types.StringType,
+		},
+	}
+	set, diags := types.ListValueFrom(ctx, setType, []setResourceModel{
+		{Name: types.StringValue("foo"), Value: types.StringValue("qux"), Type: types.StringValue("auto")},
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+
+	valuesObject := types.DynamicValue(types.ObjectValueMust(
+		map[string]attr.Type{
+			"foo":    types.StringType,
+			"nested": types.ObjectType{AttrTypes: map[string]attr.Type{"b": types.NumberType, "c": types.BoolType}},
+			"list":   types.TupleType{ElemTypes: []attr.Type{types.NumberType, types.StringType}},
+		},
+		map[string]attr.Value{
+			"foo": types.StringValue("baz"),
+			"nested": types.ObjectValueMust(
+				map[string]attr.Type{"b": types.NumberType, "c": types.BoolType},
+				map[string]attr.Value{"b": types.NumberValue(big.NewFloat(1.5)), "c": types.BoolValue(true)},
+			),
+			"list": types.TupleValueMust(
+				[]attr.Type{types.NumberType, types.StringType},
+				[]attr.Value{types.NumberValue(big.NewFloat(1)), types.StringValue("x")},
+			),
+		},
+	))
+
+	model := &HelmReleaseModel{
+		Values:       types.ListValueMust(types.StringType, []attr.Value{types.StringValue("foo: bar\nnested:\n  a: keep\n")}),
+		ValuesObject: valuesObject,
+		Set:          set,
+		SetList:      types.ListNull(types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "value": types.ListType{ElemType: types.StringType}}}),
+		SetSensitive: types.ListNull(setType),
+	}
+
+	values, diags := getValues(ctx, model, nil)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	expected := map[string]interface{}{
+		"foo": "qux",
+		"nested": map[string]interface{}{
+			"a": "keep",
+			"b": 1.5,
+			"c": true,
+		},
+		"list": []interface{}{int64(1), "x"},
+	}
+	if !reflect.DeepEqual(values, expected) {
+		t.Errorf("unexpected values:\n%#v\nexpected:\n%#v", values, expected)
+	}
+
+	model.ValuesObject = types.DynamicValue(types.ListValueMust(types.StringType, []attr.Value{types.StringValue("foo")}))
+	if _, diags := getValues(ctx, model, nil); !diags.HasError() {
+		t.Error("expected an error for a values_object that is not an object")
+	}
+}
+
+func TestDynamicValueUnknown(t *testing.T) {
+	tests := map[string]struct {
+		value    attr.Value
+		expected bool
+	}{
+		"null":    {types.DynamicNull(), false},
+		"unknown": {types.DynamicUnknown(), true},
+		"known": {types.DynamicValue(types.MapValueMust(types.StringType, map[string]attr.Value{
+			"a": types.StringValue("b"),
+		})), false},
+		"nested unknown": {types.DynamicValue(types.ObjectValueMust(
+			map[string]attr.Type{"a": types.ListType{ElemType: types.StringType}},
+			map[string]attr.Value{"a": types.ListValueMust(types.StringType, []attr.Value{types.StringUnknown()})},
+		)), true},
+	}
+	for name, tt := range tests {
+		if unknown := dynamicValueUnknown(tt.value); unknown != tt.expected {
+			t.Errorf("%s: expected %t, got %t", name, tt.expected, unknown)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/xeipuuv/gojsonschema"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"sigs.k8s.io/yaml"
+)
+
+// jsonContextSeparator separates the keys of the path of a schema violation,
+// as keys may contain dots
+const jsonContextSeparator = "\x00"
+
+// valuesSource is an element of the values_files, values, values_object, set,
+// set_list, set_sensitive or set_wo attributes, used to attribute the values schema violations to the
+// configuration element that produced the offending key
+type valuesSource struct {
+	path path.Path
+
+	// key is the path of the value set by a set-like element
+	key []string
+
+	// document is the parsed content of a values element
+	document map[string]interface{}
+}
+
+// produces returns whether the source sets the value at key, or a value
+// containing it
+func (s valuesSource) produces(key []string) bool {
+	if s.document == nil {
+		return hasKeyPrefix(key, s.key) || hasKeyPrefix(s.key, key)
+	}
+
+	var current interface{} = s.document
+	for _, k := range key {
+		switch v := current.(type) {
+		case map[string]interface{}:
+			next, ok := v[k]
+			if !ok {
+				return false
+			}
+			current = next
+		case []interface{}:
+			i, err := strconv.Atoi(k)
+			if err != nil || i < 0 || i >= len(v) {
+				return false
+			}
+			current = v[i]
+		default:
+			// The document sets a scalar where the schema expects more
+			return true
+		}
+	}
+	return true
+}
+
+func hasKeyPrefix(key, prefix []string) bool {
+	if len(prefix) > len(key) {
+		return false
+	}
+	for i := range prefix {
+		if key[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// valuesDocumentSources returns the sources of the elements of a values
+// attribute. Documents that fail to parse are skipped as they are reported
+// when merging the values.
+func valuesDocumentSources(p path.Path, elements []attr.Value) []valuesSource {
+	var sources []valuesSource
+	for i, raw := range elements {
+		value, ok := raw.(types.String)
+		if !ok || value.IsNull() || value.IsUnknown() || value.ValueString() == "" {
+			continue
+		}
+		document := map[string]interface{}{}
+		if err := yaml.Unmarshal([]byte(value.ValueString()), &document); err != nil {
+			continue
+		}
+		sources = append(sources, valuesSource{path: p.AtListIndex(i), document: document})
+	}
+	return sources
+}
+
+// valuesFileSources returns the sources of the elements of the values_files
+// attribute
+func valuesFileSources(valuesFiles []valuesFile) []valuesSource {
+	var sources []valuesSource
+	for _, f := range valuesFiles {
+		document := map[string]interface{}{}
+		if err := yaml.Unmarshal(f.content, &document); err != nil {
+			continue
+		}
+		sources = append(sources, valuesSource{path: path.Root("values_files").AtListIndex(f.index), document: document})
+	}
+	return sources
+}
+
+// valuesKeySources returns the sources of the elements of a set-like
+// attribute, addressed by index for lists and by value for sets
+func valuesKeySources(p path.Path, elements []attr.Value, isSet bool) []valuesSource {
+	var sources []valuesSource
+	for i, raw := range elements {
+		obj, ok := raw.(types.Object)
+		if !ok {
+			continue
+		}
+		name, ok := obj.Attributes()["name"].(types.String)
+		if !ok || name.IsNull() || name.IsUnknown() {
+			continue
+		}
+		elementPath := p.AtListIndex(i)
+		if isSet {
+			elementPath = p.AtSetValue(raw)
+		}
+		sources = append(sources, valuesSource{path: elementPath, key: parseValueKey(name.ValueString())})
+	}
+	return sources
+}
+
+// parseValueKey splits the name of a set-like element into the keys it sets,
+// following the notation of the helm --set flag
+func parseValueKey(name string) []string {
+	var key []string
+	var current strings.Builder
+	escaped := false
+	for _, r := range name {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == '.':
+			if current.Len() > 0 {
+				key = append(key, current.String())
+			}
+			current.Reset()
+		case r == '[':
+			if current.Len() > 0 {
+				key = append(key, current.String())
+			}
+			current.Reset()
+		case r == ']':
+			key = append(key, current.String())
+			current.Reset()
+		default:
+			current.WriteRune(r)
+		}
+	}
+	if current.Len() > 0 {
+		key = append(key, current.String())
+	}
+	return key
+}
+
+// releaseValuesSources returns the sources of the values of a helm_release,
+// in the order they are merged
+func releaseValuesSources(model *HelmReleaseModel, valuesFiles []valuesFile) []valuesSource {
+	sources := valuesFileSources(valuesFiles)
+	sources = append(sources, valuesDocumentSources(path.Root("values"), model.Values.Elements())...)
+	if valuesObject, diags := getValuesObject(model.ValuesObject); !diags.HasError() && valuesObject != nil {
+		sources = append(sources, valuesSource{path: path.Root("values_object"), document: valuesObject})
+	}
+	sources = append(sources, valuesKeySources(path.Root("set"), model.Set.Elements(), false)...)
+	sources = append(sources, valuesKeySources(path.Root("set_list"), model.SetList.Elements(), false)...)
+	sources = append(sources, valuesKeySources(path.Root("set_sensitive"), model.SetSensitive.Elements(), false)...)
+	return sources
+}
+
+// writeOnlyValuesSources returns the sources of the set_wo values of a
+// helm_release, read from its configuration
+func writeOnlyValuesSources(config *HelmReleaseModel) []valuesSource {
+	return valuesKeySources(path.Root("set_wo"), config.SetWO.Elements(), false)
+}
+
+// templateValuesSources returns the sources of the values of helm_template, in
+// the order they are merged
+func templateValuesSources(model *HelmTemplateModel, valuesFiles []valuesFile) []valuesSource {
+	sources := valuesFileSources(valuesFiles)
+	sources = append(sources, valuesDocumentSources(path.Root("values"), model.Values.Elements())...)
+	sources = append(sources, valuesKeySources(path.Root("set"), model.Set.Elements(), true)...)
+	sources = append(sources, valuesKeySources(path.Root("set_list"), model.SetList.Elements(), false)...)
+	sources = append(sources, valuesKeySources(path.Root("set_sensitive"), model.SetSensitive.Elements(), true)...)
+	sources = append(sources, valuesKeySources(path.Root("set_wo"), model.SetWO.Elements(), false)...)
+	return sources
+}
+
+// validateValuesSchema validates the values merged with the chart defaults
+// against the values.schema.json of the chart and its subcharts. Each
+// violation is reported as a diagnostic attributed to the last source setting
+// the offending key, the one which takes precedence.
+func validateValuesSchema(c *chart.Chart, values map[string]interface{}, sources []valuesSource) diag.Diagnostics {
+	var diags diag.Diagnostics
+
+	coalesced, err := chartutil.CoalesceValues(c, values)
+	if err != nil {
+		diags.AddError("Error merging chart values", fmt.Sprintf("Unable to merge the values with the defaults of chart %s: %s", c.Name(), err))
+		return diags
+	}
+
+	validateChartValuesSchema(c, coalesced, nil, sources, &diags)
+	return diags
+}
+
+func validateChartValuesSchema(c *chart.Chart, values map[string]interface{}, prefix []string, sources []valuesSource, diags *diag.Diagnostics) {
+	if len(c.Schema) > 0 {
+		violations, err := valuesSchemaViolations(c.Schema, values)
+		if err != nil {
+			diags.AddError("Invalid values schema", fmt.Sprintf("Unable to validate values against the values.schema.json of chart %s: %s", c.Name(), err))
+			return
+		}
+
+		for _, v := range violations {
+			key := append(append([]string{}, prefix...), v.key...)
+			detail := fmt.Sprintf("Value %s does not match the values.schema.json of chart %s: %s", formatValueKey(key), c.Name(), v.description)
+
+			var source *valuesSource
+			for i := len(sources) - 1; i >= 0; i-- {
+				if sources[i].produces(key) {
+					source = &sources[i]
+					break
+				}
+			}
+			if source == nil {
+				diags.AddError("Invalid chart values", detail)
+				continue
+			}
+			diags.AddAttributeError(source.path, "Invalid chart values", detail)
+		}
+	}
+
+	for _, subchart := range c.Dependencies() {
+		subchartValues, ok := values[subchart.Name()].(map[string]interface{})
+		if !ok {
+			continue
+		}
+		validateChartValuesSchema(subchart, subchartValues, append(append([]string{}, prefix...), subchart.Name()), sources, diags)
+	}
+}
+
+// valuesSchemaViolation is a values.schema.json violation at key
+type valuesSchemaViolation struct {
+	key         []string
+	description string
+}
+
+// valuesSchemaViolations validates values against schema the way helm does,
+// but returns each violation with the path of the offending key
+func valuesSchemaViolations(schema []byte, values map[string]interface{}) (violations []valuesSchemaViolation, reterr error) {
+	defer func() {
+		if r := recover(); r != nil {
+			reterr = fmt.Errorf("unable to validate schema: %s", r)
+		}
+	}()
+
+	valuesData, err := yaml.Marshal(values)
+	if err != nil {
+		return nil, err
+	}
+	valuesJSON, err := yaml.YAMLToJSON(valuesData)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.Equal(valuesJSON, []byte("null")) {
+		valuesJSON = []byte("{}")
+	}
+
+	result, err := gojsonschema.Validate(gojsonschema.NewBytesLoader(schema), gojsonschema.NewBytesLoader(valuesJSON))
+	if err != nil {
+		return nil, err
+	}
+
+	for _, e := range result.Errors() {
+		// The first key of the context is the root of the document
+		key := strings.Split(e.Context().String(jsonContextSeparator), jsonContextSeparator)[1:]
+		switch e.Type() {
+		case "required", "additional_property_not_allowed":
+			if property, ok := e.Details()["property"].(string); ok {
+				key = append(key, property)
+			}
+		}
+		violations = append(violations, valuesSchemaViolation{key: key, description: e.Description()})
+	}
+	return violations, nil
+}
+
+// formatValueKey returns key in the notation of the set attribute
+func formatValueKey(key []string) string {
+	if len(key) == 0 {
+		return "(root)"
+	}
+	var s string
+	for _, k := range key {
+		s = valuePath(s, k)
+	}
+	return s
+}
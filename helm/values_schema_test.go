@@ -0,0 +1,188 @@
+// Copyright (c) HashiCorp, Inc.
+// SPDX-License-Identifier: MPL-2.0
+
+package helm
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/hashicorp/terraform-plugin-framework/attr"
+	"github.com/hashicorp/terraform-plugin-framework/diag"
+	"github.com/hashicorp/terraform-plugin-framework/path"
+	"github.com/hashicorp/terraform-plugin-framework/types"
+	"github.com/hashicorp/terraform-plugin-testing/helper/resource"
+	"helm.sh/helm/v3/pkg/chart/loader"
+)
+
+func TestParseValueKey(t *testing.T) {
+	tests := map[string][]string{
+		"replicaCount":                          {"replicaCount"},
+		"image.tag":                             {"image", "tag"},
+		`nodeSelector.kubernetes\.io/os`:        {"nodeSelector", "kubernetes.io/os"},
+		"tolerations[0].operator":               {"tolerations", "0", "operator"},
+		"extraArgs[1]":                          {"extraArgs", "1"},
+		`podAnnotations.prometheus\.io/scrape`:  {"podAnnotations", "prometheus.io/scrape"},
+		"ingress.hosts[0].paths[1].backend.url": {"ingress", "hosts", "0", "paths", "1", "backend", "url"},
+	}
+	for name, expected := range tests {
+		if key := parseValueKey(name); !reflect.DeepEqual(key, expected) {
+			t.Errorf("parseValueKey(%q) = %q, expected %q", name, key, expected)
+		}
+	}
+}
+
+func TestValidateValuesSchema(t *testing.T) {
+	ctx := context.Background()
+
+	c, err := loader.Load(filepath.Join(testChartsPath, "schema-chart"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	setType := types.ObjectType{
+		AttrTypes: map[string]attr.Type{
+			"name":  types.StringType,
+			"type":  types.StringType,
+			"value": types.StringType,
+		},
+	}
+	set, diags := types.ListValueFrom(ctx, setType, []setResourceModel{
+		{Name: types.StringValue("replicaCount"), Value: types.StringValue("0"), Type: types.StringValue("auto")},
+		{Name: types.StringValue("image.digest"), Value: types.StringValue("sha256:abc"), Type: types.StringValue("string")},
+	})
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	model := &HelmReleaseModel{
+		Values: types.ListValueMust(types.StringType, []attr.Value{
+			types.StringValue("image:\n  pullPolicy: Sometimes\n"),
+			types.StringValue("image:\n  tag: 1.25\n"),
+		}),
+		Set:          set,
+		SetList:      types.ListNull(types.ObjectType{AttrTypes: map[string]attr.Type{"name": types.StringType, "value": types.ListType{ElemType: types.StringType}}}),
+		SetSensitive: types.ListNull(setType),
+	}
+
+	values, diags := getValues(ctx, model, nil)
+	if diags.HasError() {
+		t.Fatal(diags)
+	}
+	diags = validateValuesSchema(c, values, releaseValuesSources(model, nil))
+
+	expected := map[string]string{
+		path.Root("values").AtListIndex(0).String(): "image.pullPolicy",
+		path.Root("values").AtListIndex(1).String(): "image.tag",
+		path.Root("set").AtListIndex(0).String():    "replicaCount",
+		path.Root("set").AtListIndex(1).String():    "image.digest",
+	}
+	if len(diags) != len(expected) {
+		t.Fatalf("expected %d diagnostics, got %d: %v", len(expected), len(diags), diags)
+	}
+	for _, d := range diags {
+		withPath, ok := d.(diag.DiagnosticWithPath)
+		if !ok {
+			t.Errorf("expected the diagnostic to be attributed to an attribute: %s", d.Detail())
+			continue
+		}
+		key, ok := expected[withPath.Path().String()]
+		if !ok {
+			t.Errorf("unexpected diagnostic for %s: %s", withPath.Path(), d.Detail())
+			continue
+		}
+		if !strings.Contains(d.Detail(), fmt.Sprintf("Value %s does not match", key)) {
+			t.Errorf("expected the diagnostic for %s to report %s, got: %s", withPath.Path(), key, d.Detail())
+		}
+	}
+
+	// Violations of the chart defaults are not attributed to an attribute
+	c.Values["replicaCount"] = "one"
+	diags = validateValuesSchema(c, map[string]interface{}{}, nil)
+	if len(diags) != 1 {
+		t.Fatalf("expected a single diagnostic, got %v", diags)
+	}
+	if _, ok := diags[0].(diag.DiagnosticWithPath); ok {
+		t.Errorf("expected the diagnostic not to be attributed to an attribute: %v", diags[0])
+	}
+}
+
+func TestAccResourceRelease_valuesSchema(t *testing.T) {
+	name := randName("values-schema")
+	namespace := randName(testNamespacePrefix)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccHelmReleaseConfigValuesSchema(testResourceName, namespace, name, "replicaCount", "0"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Value replicaCount does not match the values.schema.json of chart\s+schema-chart`),
+			},
+			{
+				Config:      testAccHelmReleaseConfigValuesSchema(testResourceName, namespace, name, "image.pullPolicy", "Sometimes"),
+				PlanOnly:    true,
+				ExpectError: regexp.MustCompile(`Value image.pullPolicy does not match the values.schema.json of chart\s+schema-chart`),
+			},
+		},
+	})
+}
+
+func TestAccDataTemplate_valuesSchema(t *testing.T) {
+	name := randName("values-schema")
+	namespace := randName(testNamespacePrefix)
+
+	resource.Test(t, resource.TestCase{
+		ProtoV6ProviderFactories: protoV6ProviderFactories(),
+		Steps: []resource.TestStep{
+			{
+				Config:      testAccDataHelmTemplateConfigValuesSchema(testResourceName, namespace, name, "image.digest", "sha256:abc"),
+				ExpectError: regexp.MustCompile(`Value image.digest does not match the values.schema.json of chart\s+schema-chart`),
+			},
+			{
+				Config: testAccDataHelmTemplateConfigValuesSchema(testResourceName, namespace, name, "image.tag", "1.27"),
+				Check: resource.TestCheckResourceAttrSet(
+					fmt.Sprintf("data.helm_template.%s", testResourceName), "manifest",
+				),
+			},
+		},
+	})
+}
+
+func testAccHelmReleaseConfigValuesSchema(resource, ns, name, key, value string) string {
+	return fmt.Sprintf(`
+		resource "helm_release" "%s" {
+			name      = %q
+			namespace = %q
+			chart     = "%s/schema-chart"
+
+			set = [
+				{
+					name  = %q
+					value = %q
+				}
+			]
+		}
+	`, resource, name, ns, testChartsPath, key, value)
+}
+
+func testAccDataHelmTemplateConfigValuesSchema(resource, ns, name, key, value string) string {
+	return fmt.Sprintf(`
+		data "helm_template" "%s" {
+			name      = %q
+			namespace = %q
+			chart     = "%s/schema-chart"
+
+			set = [
+				{
+					name  = %q
+					value = %q
+				}
+			]
+		}
+	`, resource, name, ns, testChartsPath, key, value)
+}